@@ -2,12 +2,24 @@ package app
 
 import (
 	"context"
+	"sync"
 )
 
 type App struct {
-	ctx context.Context
+	ctx          context.Context
+	solveMutex   sync.Mutex
+	solveSeq     int
+	solveCancels map[int]context.CancelFunc // 正在进行的求解，{任务ID:取消函数}
+	hintMutex    sync.Mutex
+	hintCache    []*hintEntry // 最近提示过的布局的分析结果，旧的在前
+	playMutex    sync.Mutex
+	playSession  *PlaySession   // 正在进行的试玩，没有时为nil
+	solver       *SolverService // 所有求解任务都在这个工作池中运行
 }
 
 func NewApp() *App {
-	return &App{}
+	return &App{
+		solveCancels: map[int]context.CancelFunc{},
+		solver:       NewSolverService(defaultSolverWorkers, defaultSolverQueue),
+	}
 }
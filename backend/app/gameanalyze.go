@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+type GameAnalyzeReq struct {
+	JobID     int            `json:"jobId"`  // 前端指定的任务ID，用于取消求解和区分进度，为0时由后端分配
+	GameID    uint           `json:"gameId"` // 已保存的布局的ID，GameData 为空时使用已保存的布局
+	GameData  utils.GameData `json:"gameData"`
+	Metric    utils.Metric   `json:"metric"`
+	MaxStates int            `json:"maxStates"` // 为0时使用设置中的值，为-1时不限制
+}
+
+type GameAnalyzeRes struct {
+	Success          bool                    `json:"success"`
+	ErrMessage       string                  `json:"errMessage"`
+	JobID            int                     `json:"jobId"`
+	Analysis         utils.AnalyzeResult     `json:"analysis"`
+	ValidationErrors []utils.ValidationError `json:"validationErrors"`
+}
+
+// GameAnalyze 穷举布局的所有可达局面，统计到目标的距离分布
+func (a *App) GameAnalyze(req GameAnalyzeReq) (res GameAnalyzeRes) {
+	job := a.startSolveJob(req.JobID, req.GameID, req.GameData)
+	defer job.finish(&res.JobID)
+	if job.errMessage != "" {
+		return GameAnalyzeRes{
+			Success:          false,
+			ErrMessage:       job.errMessage,
+			ValidationErrors: job.validationErrors,
+		}
+	}
+
+	gameSolve := job.newGameSolve(utils.SolveOptions{
+		Metric:    req.Metric,
+		MaxStates: req.MaxStates,
+	}, job.gameData)
+	var analysis utils.AnalyzeResult
+	err := job.run(func(ctx context.Context) error {
+		var err error
+		analysis, err = gameSolve.Analyze(ctx)
+		return err
+	})
+	if err != nil {
+		return GameAnalyzeRes{
+			Success:    false,
+			ErrMessage: solveErrMessage(err, "failedToAnalyzeGame"),
+		}
+	}
+	return GameAnalyzeRes{
+		Success:  true,
+		Analysis: analysis,
+	}
+}
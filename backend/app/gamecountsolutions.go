@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+type GameCountSolutionsReq struct {
+	JobID     int            `json:"jobId"`  // 前端指定的任务ID，用于取消求解和区分进度，为0时由后端分配
+	GameID    uint           `json:"gameId"` // 已保存的布局的ID，GameData 为空时使用已保存的布局
+	GameData  utils.GameData `json:"gameData"`
+	MaxStates int            `json:"maxStates"` // 为0时使用设置中的值，为-1时不限制
+}
+
+type GameCountSolutionsRes struct {
+	Success          bool                    `json:"success"`
+	ErrMessage       string                  `json:"errMessage"`
+	JobID            int                     `json:"jobId"`
+	Count            utils.SolutionCount     `json:"count"`
+	ValidationErrors []utils.ValidationError `json:"validationErrors"`
+}
+
+// GameCountSolutions 统计最优解法数和死局数，同一棋子的连续移动算一步
+func (a *App) GameCountSolutions(req GameCountSolutionsReq) (res GameCountSolutionsRes) {
+	job := a.startSolveJob(req.JobID, req.GameID, req.GameData)
+	defer job.finish(&res.JobID)
+	if job.errMessage != "" {
+		return GameCountSolutionsRes{
+			Success:          false,
+			ErrMessage:       job.errMessage,
+			ValidationErrors: job.validationErrors,
+		}
+	}
+
+	gameSolve := job.newGameSolve(utils.SolveOptions{
+		Metric:    utils.MetricPiece,
+		MaxStates: req.MaxStates,
+	}, job.gameData)
+	var count utils.SolutionCount
+	err := job.run(func(ctx context.Context) error {
+		var err error
+		count, err = gameSolve.CountSolutions(ctx)
+		return err
+	})
+	if err != nil {
+		return GameCountSolutionsRes{
+			Success:    false,
+			ErrMessage: solveErrMessage(err, "failedToAnalyzeGame"),
+		}
+	}
+	return GameCountSolutionsRes{
+		Success: true,
+		Count:   count,
+	}
+}
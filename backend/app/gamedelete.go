@@ -33,6 +33,7 @@ func (a *App) GameDelete(req GameDeleteReq) GameDeleteRes {
 			ErrMessage: "failedToDeleteGame",
 		}
 	}
+	a.forgetHint(req.ID)
 	return GameDeleteRes{
 		Success: true,
 	}
@@ -18,6 +18,7 @@ type ExportGameItem struct {
 	Name      string   `json:"name"`
 	Tags      []string `json:"tags"`
 	GameShape string   `json:"gameShape"`
+	Goal      string   `json:"goal,omitempty"`
 	Md5       string   `json:"md5"`
 }
 
@@ -79,6 +80,7 @@ func (a *App) GameExport(req GameExportReq) GameExportRes {
 			Name:      game.Name,
 			Tags:      tags,
 			GameShape: game.GameShape,
+			Goal:      game.Goal,
 			Md5:       game.Md5,
 		})
 	}
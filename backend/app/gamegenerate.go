@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// GameGenerateReq 生成选项，另带任务ID
+// 选项中的 MaxStates 和 MaxMemory 为0时使用设置中的值，为-1时不限制
+type GameGenerateReq struct {
+	JobID int `json:"jobId"` // 前端指定的任务ID，用于取消生成，为0时由后端分配
+	utils.GenerateOptions
+}
+
+type GameGenerateRes struct {
+	Success    bool                 `json:"success"`
+	ErrMessage string               `json:"errMessage"`
+	JobID      int                  `json:"jobId"`
+	Generated  utils.GenerateResult `json:"generated"`
+}
+
+// GameGenerate 随机生成最优解长度在指定范围内的布局
+func (a *App) GameGenerate(req GameGenerateReq) (res GameGenerateRes) {
+	jobID, ctx, done, err := a.beginSolve(req.JobID)
+	defer done()
+	defer func() {
+		res.JobID = jobID
+	}()
+	if err != nil {
+		return GameGenerateRes{
+			Success:    false,
+			ErrMessage: solveErrMessage(err, ""),
+		}
+	}
+	options := req.GenerateOptions
+	// 每次尝试的局面数和内存使用设置中的限制，用时由 TimeBudget 限制
+	limits := utils.SolveOptions{
+		MaxStates: options.MaxStates,
+		MaxMemory: options.MaxMemory,
+	}
+	applyLimits(&limits)
+	options.MaxStates, options.MaxMemory = limits.MaxStates, limits.MaxMemory
+
+	var generated utils.GenerateResult
+	err = a.solver.Do(ctx, func(ctx context.Context) error {
+		var err error
+		generated, err = utils.Generate(ctx, options)
+		return err
+	})
+	if errors.Is(err, utils.ErrInvalidOptions) {
+		return GameGenerateRes{
+			Success:    false,
+			ErrMessage: "invalidOptions",
+		}
+	}
+	if errors.Is(err, utils.ErrGenerateTimeout) {
+		return GameGenerateRes{
+			Success:    false,
+			ErrMessage: "generateTimeout",
+		}
+	}
+	if err != nil {
+		return GameGenerateRes{
+			Success:    false,
+			ErrMessage: solveErrMessage(err, "failedToGenerateGame"),
+		}
+	}
+	return GameGenerateRes{
+		Success:   true,
+		Generated: generated,
+	}
+}
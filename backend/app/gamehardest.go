@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+type GameHardestReq struct {
+	JobID     int            `json:"jobId"`  // 前端指定的任务ID，用于取消求解和区分进度，为0时由后端分配
+	GameID    uint           `json:"gameId"` // 已保存的布局的ID，GameData 为空时使用已保存的布局
+	GameData  utils.GameData `json:"gameData"`
+	Metric    utils.Metric   `json:"metric"`
+	MaxStates int            `json:"maxStates"` // 为0时使用设置中的值，为-1时不限制
+}
+
+type GameHardestRes struct {
+	Success          bool                    `json:"success"`
+	ErrMessage       string                  `json:"errMessage"`
+	JobID            int                     `json:"jobId"`
+	Hardest          utils.HardestResult     `json:"hardest"`
+	ValidationErrors []utils.ValidationError `json:"validationErrors"`
+}
+
+// GameHardest 找出同一组棋子可以到达的最难开局，交给设计器修改或保存
+func (a *App) GameHardest(req GameHardestReq) (res GameHardestRes) {
+	job := a.startSolveJob(req.JobID, req.GameID, req.GameData)
+	defer job.finish(&res.JobID)
+	if job.errMessage != "" {
+		return GameHardestRes{
+			Success:          false,
+			ErrMessage:       job.errMessage,
+			ValidationErrors: job.validationErrors,
+		}
+	}
+
+	gameSolve := job.newGameSolve(utils.SolveOptions{
+		Metric:    req.Metric,
+		MaxStates: req.MaxStates,
+	}, job.gameData)
+	var hardest utils.HardestResult
+	err := job.run(func(ctx context.Context) error {
+		var err error
+		hardest, err = gameSolve.FindHardest(ctx, job.gameData)
+		return err
+	})
+	if err != nil {
+		return GameHardestRes{
+			Success:    false,
+			ErrMessage: solveErrMessage(err, "noSolution"),
+		}
+	}
+	return GameHardestRes{
+		Success: true,
+		Hardest: hardest,
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// 局面的Md5由后端按布局的规范摘要计算，前端提交的和导入文件中的md5都不可信，
+// 摘要算法改变时 utils.PuzzleHashVersion 加一，启动时重新计算旧版本的摘要
+
+var errInvalidGame = errors.New("invalid game")
+
+// hashGame 重新计算局面的摘要，布局无效时返回错误
+func hashGame(game *models.Game) error {
+	gameData, err := gameDataOf(*game)
+	if err != nil {
+		return errInvalidGame
+	}
+	if errs := utils.Validate(gameData); len(errs) > 0 {
+		return errInvalidGame
+	}
+	game.Md5 = utils.PuzzleMd5(gameData)
+	game.HashVersion = utils.PuzzleHashVersion
+	return nil
+}
+
+// findDuplicate 查找摘要相同的其他局面，没有时返回的ID为0
+func findDuplicate(game models.Game) models.Game {
+	duplicate := models.Game{}
+	models.GetDB().Where("md5 = ? AND id <> ?", game.Md5, game.ID).Limit(1).Find(&duplicate)
+	return duplicate
+}
+
+// rehashGames 重新计算摘要版本不是当前版本的局面
+// 旧摘要不同而规范摘要相同的局面无法都更新，保留原来的摘要，下次启动时再尝试
+func rehashGames() {
+	db := models.GetDB()
+	var games []models.Game
+	db.Where("hash_version <> ?", utils.PuzzleHashVersion).Find(&games)
+	for _, game := range games {
+		if hashGame(&game) != nil || findDuplicate(game).ID != 0 {
+			continue
+		}
+		db.Model(&game).Updates(map[string]interface{}{
+			"md5":          game.Md5,
+			"hash_version": game.HashVersion,
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package app
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+const hintCacheSize = 4 // 最多缓存几个布局的分析结果
+
+type GameHintReq struct {
+	JobID     int            `json:"jobId"` // 前端指定的任务ID，用于取消求解和区分进度，为0时由后端分配
+	GameID    uint           `json:"gameId"`
+	GameData  utils.GameData `json:"gameData"`
+	Metric    utils.Metric   `json:"metric"`
+	Positions []utils.Pos    `json:"positions"` // 当前每个棋子的位置，顺序与 GameData.PieceList 相同
+}
+
+type GameHintRes struct {
+	Success          bool                    `json:"success"`
+	ErrMessage       string                  `json:"errMessage"`
+	JobID            int                     `json:"jobId"`
+	Hint             utils.HintResult        `json:"hint"`
+	ValidationErrors []utils.ValidationError `json:"validationErrors"`
+}
+
+// hintEntry 一个布局的分析结果，状态空间太大无法穷举时只做标记
+// 分析在锁外进行，ready 关闭之前其他相同布局的请求等待它完成
+type hintEntry struct {
+	key       string
+	gameID    uint
+	ready     chan struct{}
+	err       error      // 分析失败的原因，失败的条目会从缓存中移除
+	mutex     sync.Mutex // 同一分析结果上的提示共用求解器的缓冲，需要依次进行
+	gameSolve *utils.GameSolve
+	tooLarge  bool
+}
+
+// GameHint 给出当前局面最优路线上的下一步
+// 第一次请求时穷举整个布局并缓存分析结果，之后的提示只需查表；状态空间太大时改为从当前局面求解
+func (a *App) GameHint(req GameHintReq) (res GameHintRes) {
+	job := a.startSolveJob(req.JobID, req.GameID, req.GameData)
+	defer job.finish(&res.JobID)
+	if job.errMessage != "" {
+		return GameHintRes{
+			Success:          false,
+			ErrMessage:       job.errMessage,
+			ValidationErrors: job.validationErrors,
+		}
+	}
+	// 只给了布局ID时使用读取到的布局，分析和缓存键都按它计算
+	req.GameData = job.gameData
+	entry, err := a.hintAnalysis(job, req)
+	if err == nil && !entry.tooLarge {
+		entry.mutex.Lock()
+		hint, err := entry.gameSolve.Hint(req.Positions)
+		entry.mutex.Unlock()
+		if err == nil {
+			return GameHintRes{
+				Success: true,
+				Hint:    hint,
+			}
+		}
+		if !errors.Is(err, utils.ErrUnknownPosition) {
+			return GameHintRes{
+				Success:    false,
+				ErrMessage: "invalidPositions",
+			}
+		}
+	} else if err != nil {
+		return GameHintRes{
+			Success:    false,
+			ErrMessage: solveErrMessage(err, "noSolution"),
+		}
+	}
+
+	// 从当前局面重新求解，取解法的第一步
+	game := req.GameData
+	game.PieceList = append([]utils.Piece{}, game.PieceList...)
+	if len(req.Positions) != len(game.PieceList) {
+		return GameHintRes{
+			Success:    false,
+			ErrMessage: "invalidPositions",
+		}
+	}
+	for i, pos := range req.Positions {
+		game.PieceList[i].Position = pos
+	}
+	gameSolve := job.newGameSolve(utils.SolveOptions{Metric: req.Metric}, game)
+	var result utils.SolveResult
+	err = job.run(func(ctx context.Context) error {
+		var err error
+		result, err = gameSolve.Solve(ctx)
+		return err
+	})
+	// 取消、超出限制等没有求解完成时返回错误，无解时仍然返回提示
+	if errMessage := solveErrMessage(err, ""); errMessage != "" {
+		return GameHintRes{
+			Success:    false,
+			ErrMessage: errMessage,
+		}
+	}
+	hint := utils.HintResult{
+		Metric: result.Metric,
+		Step: utils.Step{
+			PieceIndex: -1,
+			Direction:  []int16{0, 0},
+		},
+	}
+	if err == nil {
+		hint.Solvable = true
+		hint.Distance = result.Length
+		if len(result.Steps) > 0 {
+			hint.Step = result.Steps[0]
+		}
+	}
+	return GameHintRes{
+		Success: true,
+		Hint:    hint,
+	}
+}
+
+// hintAnalysis 取出缓存的分析结果，没有时穷举布局并加入缓存
+// 只在查找和修改缓存时持有锁，正在分析的布局由第一个请求完成，其余请求等待
+func (a *App) hintAnalysis(job *solveJob, req GameHintReq) (*hintEntry, error) {
+	key := hintKey(req.GameData, req.Metric)
+	for {
+		entry, owner := a.findHint(key, req.GameID)
+		if owner {
+			a.analyzeHint(job, entry, req)
+			return entry, entry.err
+		}
+		select {
+		case <-entry.ready:
+		case <-job.ctx.Done():
+			return nil, job.ctx.Err()
+		}
+		if entry.err == nil {
+			return entry, nil
+		}
+		// 其他请求的分析被取消或失败，由这个请求重新分析
+	}
+}
+
+// findHint 查找缓存的条目，没有时加入一个正在分析的条目，返回的owner表示由调用方负责分析
+func (a *App) findHint(key string, gameID uint) (*hintEntry, bool) {
+	a.hintMutex.Lock()
+	defer a.hintMutex.Unlock()
+	for _, entry := range a.hintCache {
+		if entry.key == key {
+			return entry, false
+		}
+	}
+	entry := &hintEntry{
+		key:    key,
+		gameID: gameID,
+		ready:  make(chan struct{}),
+	}
+	a.hintCache = append(a.hintCache, entry)
+	if len(a.hintCache) > hintCacheSize {
+		a.hintCache = a.hintCache[1:]
+	}
+	return entry, true
+}
+
+// analyzeHint 穷举布局，完成后通知等待的请求，失败时把条目移出缓存
+func (a *App) analyzeHint(job *solveJob, entry *hintEntry, req GameHintReq) {
+	defer close(entry.ready)
+	gameSolve := job.newGameSolve(utils.SolveOptions{Metric: req.Metric}, req.GameData)
+	err := job.run(func(ctx context.Context) error {
+		_, err := gameSolve.Analyze(ctx)
+		return err
+	})
+	if errors.Is(err, utils.ErrLimitExceeded) {
+		entry.tooLarge = true
+		return
+	}
+	if err != nil {
+		entry.err = err
+		a.removeHint(entry)
+		return
+	}
+	entry.gameSolve = gameSolve
+}
+
+// hintKey 分析结果的缓存键，由规范摘要和计步方式决定
+// 提示中的棋子索引按请求中的棋子顺序，所以键中还包含棋子列表的顺序
+func hintKey(game utils.GameData, metric utils.Metric) string {
+	if metric == "" {
+		metric = utils.MetricPiece
+	}
+	order, _ := json.Marshal(struct {
+		PieceList      []utils.Piece
+		KingPieceIndex int16
+	}{game.PieceList, game.KingPieceIndex})
+	sum := sha1.Sum(order)
+	return fmt.Sprintf("%s:%s:%x", utils.PuzzleHash(game), metric, sum[:8])
+}
+
+func (a *App) removeHint(target *hintEntry) {
+	a.hintMutex.Lock()
+	defer a.hintMutex.Unlock()
+	cache := a.hintCache[:0]
+	for _, entry := range a.hintCache {
+		if entry != target {
+			cache = append(cache, entry)
+		}
+	}
+	a.hintCache = cache
+}
+
+// forgetHint 布局修改或删除后，丢弃为它分析的结果
+func (a *App) forgetHint(gameID uint) {
+	a.hintMutex.Lock()
+	defer a.hintMutex.Unlock()
+	cache := a.hintCache[:0]
+	for _, entry := range a.hintCache {
+		if entry.gameID != gameID {
+			cache = append(cache, entry)
+		}
+	}
+	a.hintCache = cache
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// lineGame 1行n列的棋盘，一枚单格棋子从from走到to即获胜
+func lineGame(cols int16, from int16, to int16) utils.GameData {
+	return utils.GameData{
+		BoardRows:      1,
+		BoardCols:      cols,
+		KingPieceIndex: 0,
+		KingWinPos:     utils.Pos{-1, -1},
+		PieceList:      []utils.Piece{{Shape: utils.Shape{{true}}, Position: utils.Pos{0, from}}},
+		Goal: &utils.Goal{
+			Pieces: []utils.PieceGoal{{PieceIndex: 0, Position: utils.Pos{0, to}}},
+		},
+	}
+}
+
+// TestHintUnsavedLayouts 未保存的布局ID都为0，每个布局仍然使用自己的分析结果
+func TestHintUnsavedLayouts(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	cases := []struct {
+		game      utils.GameData
+		direction []int16
+	}{
+		{lineGame(3, 0, 2), []int16{0, 2}},
+		{lineGame(3, 2, 0), []int16{0, -2}},
+		{lineGame(4, 3, 1), []int16{0, -2}},
+	}
+	for i, c := range cases {
+		res := a.GameHint(GameHintReq{
+			GameData:  c.game,
+			Positions: []utils.Pos{c.game.PieceList[0].Position},
+		})
+		if !res.Success {
+			t.Errorf("case %d: %s", i, res.ErrMessage)
+			continue
+		}
+		direction := res.Hint.Step.Direction
+		if res.Hint.Distance != 1 || direction[0] != c.direction[0] || direction[1] != c.direction[1] {
+			t.Errorf("case %d: distance %d, direction %v, want 1, %v", i, res.Hint.Distance, direction, c.direction)
+		}
+	}
+}
+
+// TestHintSharedAnalysis 默认计步方式与按棋子计步共用分析结果，同时请求同一布局只分析一次
+func TestHintSharedAnalysis(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	game := lineGame(5, 0, 4)
+	var wg sync.WaitGroup
+	for i, metric := range []utils.Metric{"", utils.MetricPiece, "", utils.MetricPiece} {
+		wg.Add(1)
+		go func(position int16, metric utils.Metric) {
+			defer wg.Done()
+			res := a.GameHint(GameHintReq{
+				GameData:  game,
+				Metric:    metric,
+				Positions: []utils.Pos{{0, position}},
+			})
+			if !res.Success || res.Hint.Distance != 1 {
+				t.Errorf("position %d: %+v", position, res)
+			}
+		}(int16(i), metric)
+	}
+	wg.Wait()
+	if len(a.hintCache) != 1 {
+		t.Errorf("%d cache entries, want 1", len(a.hintCache))
+	}
+	a.forgetHint(0)
+	if len(a.hintCache) != 0 {
+		t.Errorf("%d cache entries after forget, want 0", len(a.hintCache))
+	}
+}
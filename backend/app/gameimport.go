@@ -8,10 +8,11 @@ import (
 )
 
 type GameImportRes struct {
-	Success     bool   `json:"success"`
-	ErrMessage  string `json:"errMessage"`
-	RepeatCount int    `json:"repeatCount"`
-	Count       int    `json:"count"`
+	Success      bool   `json:"success"`
+	ErrMessage   string `json:"errMessage"`
+	RepeatCount  int    `json:"repeatCount"`
+	InvalidCount int    `json:"invalidCount"` // 布局无效而跳过的局面数
+	Count        int    `json:"count"`
 }
 
 func (a *App) GameImport() GameImportRes {
@@ -37,6 +38,11 @@ func (a *App) GameImport() GameImportRes {
 			ErrMessage: "failedToParseFile",
 		}
 	}
+	return importGames(data)
+}
+
+// importGames 把导出文件中的局面和标签加入数据库，跳过重复和无效的局面
+func importGames(data ExportData) GameImportRes {
 	db := models.GetDB()
 	tags := []models.Tag{}
 	db.Find(&tags)
@@ -55,31 +61,37 @@ func (a *App) GameImport() GameImportRes {
 	}
 	count := 0
 	repeatCount := 0
-	for _, game := range data.Games {
-		checkGame := models.Game{}
-		db.Where("md5 = ?", game.Md5).First(&checkGame)
-		if checkGame.ID == 0 {
+	invalidCount := 0
+	for _, item := range data.Games {
+		game := models.Game{
+			Name:      item.Name,
+			GameShape: item.GameShape,
+			Goal:      item.Goal,
+		}
+		// 文件中的md5不可信，按布局重新计算，布局无效的局面不导入
+		if hashGame(&game) != nil {
+			invalidCount++
+			continue
+		}
+		if findDuplicate(game).ID == 0 {
 			gameTags := []*models.Tag{}
-			for _, tagName := range game.Tags {
+			for _, tagName := range item.Tags {
 				tagID, _ := tagMap[tagName]
 				if tagID > 0 {
 					gameTags = append(gameTags, &models.Tag{ID: tagID})
 				}
 			}
-			db.Create(&models.Game{
-				Name:      game.Name,
-				GameShape: game.GameShape,
-				Md5:       game.Md5,
-				Tags:      gameTags,
-			})
+			game.Tags = gameTags
+			db.Create(&game)
 			count++
 		} else {
 			repeatCount++
 		}
 	}
 	return GameImportRes{
-		Success:     true,
-		Count:       count,
-		RepeatCount: repeatCount,
+		Success:      true,
+		Count:        count,
+		RepeatCount:  repeatCount,
+		InvalidCount: invalidCount,
 	}
 }
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// TestImportRepairsMd5 导入旧版导出文件时，前端计算的md5换成后端的规范摘要
+func TestImportRepairsMd5(t *testing.T) {
+	byteValue, err := ioutil.ReadFile("../utils/testdata/baseline-export.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := ExportData{}
+	if err := json.Unmarshal(byteValue, &data); err != nil {
+		t.Fatal(err)
+	}
+	fileMd5 := map[string]string{}
+	for _, item := range data.Games {
+		fileMd5[item.Name] = item.Md5
+	}
+	// 无效的局面不导入
+	data.Games = append(data.Games, ExportGameItem{Name: "invalid", GameShape: "[[-2]]", Md5: "invalid"})
+
+	res := importGames(data)
+	if !res.Success || res.Count != 3 || res.RepeatCount != 0 || res.InvalidCount != 1 {
+		t.Fatalf("first import: %+v", res)
+	}
+	for name, md5 := range fileMd5 {
+		game := models.Game{}
+		models.GetDB().Where("name = ?", name).Limit(1).Find(&game)
+		gameData, err := gameDataOf(game)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if game.Md5 == md5 || game.Md5 != utils.PuzzleMd5(gameData) || game.HashVersion != utils.PuzzleHashVersion {
+			t.Errorf("%s: md5 %s, version %d, file md5 %s", name, game.Md5, game.HashVersion, md5)
+		}
+	}
+
+	res = importGames(data)
+	if !res.Success || res.Count != 0 || res.RepeatCount != 3 || res.InvalidCount != 1 {
+		t.Errorf("second import: %+v", res)
+	}
+}
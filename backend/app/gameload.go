@@ -0,0 +1,24 @@
+package app
+
+import (
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// loadGameData 请求中没有布局而有布局ID时，从数据库读取已保存的布局，失败时返回错误信息
+func loadGameData(gameID uint, gameData *utils.GameData) string {
+	if gameID == 0 || len(gameData.PieceList) > 0 {
+		return ""
+	}
+	game := models.Game{}
+	models.GetDB().Limit(1).Find(&game, gameID)
+	if game.ID == 0 {
+		return "gameNotFound"
+	}
+	stored, err := gameDataOf(game)
+	if err != nil {
+		return "invalidGameData"
+	}
+	*gameData = stored
+	return ""
+}
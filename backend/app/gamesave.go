@@ -2,24 +2,78 @@ package app
 
 import (
 	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
 	"gorm.io/gorm"
 )
 
 type GameSaveRes struct {
-	Success    bool        `json:"success"`
-	ErrMessage string      `json:"errMessage"`
-	Game       models.Game `json:"game"`
+	Success          bool                    `json:"success"`
+	ErrMessage       string                  `json:"errMessage"`
+	Game             models.Game             `json:"game"`
+	ValidationErrors []utils.ValidationError `json:"validationErrors"`
 }
 
 func (a *App) GameSave(game models.Game) GameSaveRes {
+	var gameData utils.GameData
+	if game.GameData != nil {
+		gameData = *game.GameData
+	} else {
+		var err error
+		if gameData, err = gameDataOf(game); err != nil {
+			return GameSaveRes{
+				Success:    false,
+				ErrMessage: "invalidGameData",
+			}
+		}
+	}
+	if errs := utils.Validate(gameData); len(errs) > 0 {
+		return GameSaveRes{
+			Success:          false,
+			ErrMessage:       "invalidGameData",
+			ValidationErrors: errs,
+		}
+	}
+	if game.GameData != nil {
+		// 布局数据中王棋在最前面，获胜条件中的棋子索引按同样的顺序保存
+		game.GameShape = utils.GameData2GameShape(gameData)
+		game.Goal = utils.GameData2Goal(gameData)
+		game.GameData = nil
+	}
+
+	// 前端提交的md5可能由旧算法计算或者与布局不符，以后端计算的摘要为准
+	game.Md5 = utils.PuzzleMd5(gameData)
+	game.HashVersion = utils.PuzzleHashVersion
+	if duplicate := findDuplicate(game); duplicate.ID != 0 {
+		return GameSaveRes{
+			Success:    false,
+			ErrMessage: "gameAlreadyExists",
+			Game:       duplicate,
+		}
+	}
+
 	db := models.GetDB()
 	if game.ID != 0 {
+		// 求解统计只由求解写入，布局改变之后旧的统计不再有效
+		saved := models.Game{}
+		db.First(&saved, game.ID)
+		game.SolveStats = ""
 		err := db.Transaction(func(tx *gorm.DB) error {
 			err := tx.Model(&game).Association("Tags").Replace(game.Tags)
 			if err != nil {
 				return err
 			}
-			tx.Model(&game).Updates(&game)
+			if err := tx.Model(&game).Updates(&game).Error; err != nil {
+				return err
+			}
+			// Updates 跳过零值字段，清除获胜条件时需要单独写入
+			if err := tx.Model(&game).Update("goal", game.Goal).Error; err != nil {
+				return err
+			}
+			if saved.GameShape != game.GameShape || saved.Goal != game.Goal {
+				tx.Model(&game).Update("solve_stats", "")
+			} else {
+				game.SolveStats = saved.SolveStats
+			}
 			return nil
 		})
 		if err != nil {
@@ -28,16 +82,14 @@ func (a *App) GameSave(game models.Game) GameSaveRes {
 				ErrMessage: "failedToSaveGame",
 			}
 		}
-	} else {
-		checkHasGame := models.Game{}
-		db.First(&checkHasGame, "md5 = ?", game.Md5)
-		if checkHasGame.ID != 0 {
-			return GameSaveRes{
-				Success:    false,
-				ErrMessage: "gameAlreadyExists",
-				Game:       checkHasGame,
+		a.forgetHint(game.ID)
+		// 布局改变之后，旧布局缓存的解法不再需要
+		if saved.GameShape != game.GameShape || saved.Goal != game.Goal {
+			if savedData, err := gameDataOf(saved); err == nil {
+				forgetSolutions(savedData)
 			}
 		}
+	} else {
 		db.Create(&game)
 	}
 	return GameSaveRes{
@@ -45,3 +97,8 @@ func (a *App) GameSave(game models.Game) GameSaveRes {
 		Game:    game,
 	}
 }
+
+// gameDataOf 把保存的布局数据和获胜条件转换成布局
+func gameDataOf(game models.Game) (utils.GameData, error) {
+	return utils.GameShapeGoal2GameData(game.GameShape, game.Goal)
+}
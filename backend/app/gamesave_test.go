@@ -0,0 +1,51 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// kingSecondGame 王棋是第二枚棋子，获胜条件要求第一枚棋子右移一格
+func kingSecondGame() utils.GameData {
+	return utils.GameData{
+		BoardRows:      1,
+		BoardCols:      4,
+		KingPieceIndex: 1,
+		KingWinPos:     utils.Pos{-1, -1},
+		PieceList: []utils.Piece{
+			{Shape: utils.Shape{{true}}, Position: utils.Pos{0, 0}},
+			{Shape: utils.Shape{{true}}, Position: utils.Pos{0, 3}},
+		},
+		Goal: &utils.Goal{
+			Pieces: []utils.PieceGoal{{PieceIndex: 0, Position: utils.Pos{0, 1}}},
+		},
+	}
+}
+
+// TestGameSaveKingNotFirst 王棋不在最前面时，保存的获胜条件仍然指向原来的棋子
+func TestGameSaveKingNotFirst(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	game := kingSecondGame()
+	saved := saveGame(t, a, "king second", game)
+	if saved.Md5 != utils.PuzzleMd5(game) || saved.GameData != nil {
+		t.Errorf("saved %+v", saved)
+	}
+	loaded := utils.GameData{}
+	if errMessage := loadGameData(saved.ID, &loaded); errMessage != "" {
+		t.Fatal(errMessage)
+	}
+	if loaded.KingPieceIndex != 0 || loaded.Goal.Pieces[0].PieceIndex != 1 || loaded.PieceList[1].Position[1] != 0 {
+		t.Errorf("loaded %+v, goal %+v", loaded, loaded.Goal)
+	}
+	if utils.PuzzleHash(loaded) != utils.PuzzleHash(game) {
+		t.Errorf("hash %s, want %s", utils.PuzzleHash(loaded), utils.PuzzleHash(game))
+	}
+
+	// 已保存的布局数据和获胜条件原样提交，比如只修改名称时，仍然是同一个布局
+	saved.Name = "renamed"
+	if res := a.GameSave(saved); !res.Success || res.Game.Md5 != saved.Md5 || res.Game.Goal != saved.Goal {
+		t.Errorf("rename: %+v", res)
+	}
+}
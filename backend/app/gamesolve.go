@@ -1,25 +1,162 @@
 package app
 
-import "github.com/addlete/custom-klotski/backend/utils"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+type GameSolveReq struct {
+	JobID        int             `json:"jobId"`  // 前端指定的任务ID，用于取消求解和区分进度，为0时由后端分配
+	GameID       uint            `json:"gameId"` // 已保存的布局的ID，不为0时求解成功后把统计保存到布局，GameData 为空时使用已保存的布局
+	GameData     utils.GameData  `json:"gameData"`
+	Metric       utils.Metric    `json:"metric"`
+	Algorithm    utils.Algorithm `json:"algorithm"`
+	Heuristic    string          `json:"heuristic"`
+	MaxStates    int             `json:"maxStates"` // 以下三项限制为0时使用设置中的值，为-1时本次求解不限制
+	MaxTime      int             `json:"maxTime"`
+	MaxMemory    int             `json:"maxMemory"`
+	Workers      int             `json:"workers"`
+	NoSymmetry   bool            `json:"noSymmetry"`
+	ScratchDir   string          `json:"scratchDir"`
+	MemoryBudget int             `json:"memoryBudget"`
+	Force        bool            `json:"force"` // 不使用缓存的解法，重新求解
+}
 
 type GameSolveRes struct {
-	Success    bool         `json:"success"`
-	ErrMessage string       `json:"errMessage"`
-	Solution   []utils.Step `json:"solution"`
+	Success          bool                    `json:"success"`
+	ErrMessage       string                  `json:"errMessage"`
+	JobID            int                     `json:"jobId"`
+	Solution         []utils.Step            `json:"solution"`
+	Metric           utils.Metric            `json:"metric"`
+	Algorithm        utils.Algorithm         `json:"algorithm"`
+	Length           int                     `json:"length"`
+	Optimal          bool                    `json:"optimal"`
+	ForwardExplored  int                     `json:"forwardExplored"`
+	BackwardExplored int                     `json:"backwardExplored"`
+	Stats            utils.SolveStats        `json:"stats"`
+	Limit            string                  `json:"limit"`  // 超出的限制，只在 limitExceeded 时有值
+	Cached           bool                    `json:"cached"` // 是否为缓存的解法
+	ValidationErrors []utils.ValidationError `json:"validationErrors"`
 }
 
-func (a *App) GameSolve(gameData utils.GameData) GameSolveRes {
-	gameSolve := utils.GameSolve{}
-	gameSolve.Init(gameData)
-	solution, err := gameSolve.Solve()
+func (a *App) GameSolve(req GameSolveReq) (res GameSolveRes) {
+	job := a.startSolveJob(req.JobID, req.GameID, req.GameData)
+	defer job.finish(&res.JobID)
+	if job.errMessage != "" {
+		return GameSolveRes{
+			Success:          false,
+			ErrMessage:       job.errMessage,
+			ValidationErrors: job.validationErrors,
+		}
+	}
+
+	metric := req.Metric
+	if metric == "" {
+		metric = utils.MetricPiece
+	}
+	if !req.Force {
+		if result, ok := loadSolution(job.gameData, metric); ok {
+			return GameSolveRes{
+				Success:   true,
+				Solution:  result.Steps,
+				Metric:    result.Metric,
+				Algorithm: result.Algorithm,
+				Length:    result.Length,
+				Optimal:   result.Optimal,
+				Stats:     result.Stats,
+				Cached:    true,
+			}
+		}
+	}
+
+	gameSolve := job.newGameSolve(utils.SolveOptions{
+		Metric:       metric,
+		Algorithm:    req.Algorithm,
+		Heuristic:    req.Heuristic,
+		MaxStates:    req.MaxStates,
+		MaxTime:      req.MaxTime,
+		MaxMemory:    req.MaxMemory,
+		Workers:      req.Workers,
+		NoSymmetry:   req.NoSymmetry,
+		ScratchDir:   req.ScratchDir,
+		MemoryBudget: req.MemoryBudget,
+	}, job.gameData)
+	var result utils.SolveResult
+	err := job.run(func(ctx context.Context) error {
+		var err error
+		result, err = gameSolve.Solve(ctx)
+		return err
+	})
+	// 超出限制时返回已经得到的统计，方便调整限制
+	if errors.Is(err, utils.ErrLimitExceeded) {
+		return GameSolveRes{
+			Success:          false,
+			ErrMessage:       solveErrMessage(err, "noSolution"),
+			Metric:           result.Metric,
+			Algorithm:        result.Algorithm,
+			ForwardExplored:  result.ForwardExplored,
+			BackwardExplored: result.BackwardExplored,
+			Stats:            result.Stats,
+			Limit:            utils.LimitOf(err),
+		}
+	}
 	if err != nil {
 		return GameSolveRes{
 			Success:    false,
-			ErrMessage: "noSolution",
+			ErrMessage: solveErrMessage(err, "noSolution"),
 		}
 	}
+	storeSolution(job.gameData, result)
+	if req.GameID != 0 {
+		saveSolveStats(req.GameID, job.gameData, result)
+	}
 	return GameSolveRes{
-		Success:  true,
-		Solution: solution,
+		Success:          true,
+		Solution:         result.Steps,
+		Metric:           result.Metric,
+		Algorithm:        result.Algorithm,
+		Length:           result.Length,
+		Optimal:          result.Optimal,
+		ForwardExplored:  result.ForwardExplored,
+		BackwardExplored: result.BackwardExplored,
+		Stats:            result.Stats,
 	}
 }
+
+// GameSolveStats 保存在布局中的求解统计
+type GameSolveStats struct {
+	Metric    utils.Metric     `json:"metric"`
+	Algorithm utils.Algorithm  `json:"algorithm"`
+	Length    int              `json:"length"`
+	Optimal   bool             `json:"optimal"`
+	Stats     utils.SolveStats `json:"stats"`
+}
+
+// saveSolveStats 把求解统计保存到布局，同一布局只保留最近一次的统计
+// 求解的是设计器中还没有保存的修改时，统计不属于已保存的布局，不保存
+func saveSolveStats(gameID uint, gameData utils.GameData, result utils.SolveResult) {
+	db := models.GetDB()
+	game := models.Game{}
+	db.First(&game, gameID)
+	savedData, err := gameDataOf(game)
+	if game.ID == 0 || err != nil || !sameLayout(savedData, gameData) {
+		return
+	}
+	data, _ := json.Marshal(GameSolveStats{
+		Metric:    result.Metric,
+		Algorithm: result.Algorithm,
+		Length:    result.Length,
+		Optimal:   result.Optimal,
+		Stats:     result.Stats,
+	})
+	db.Model(&models.Game{ID: gameID}).Update("solve_stats", string(data))
+}
+
+// sameLayout 两个布局是否为同一个谜题，按 utils.PuzzleHash 比较，棋子顺序不同也视为相同
+func sameLayout(a utils.GameData, b utils.GameData) bool {
+	return utils.PuzzleHash(a) == utils.PuzzleHash(b)
+}
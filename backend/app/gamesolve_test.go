@@ -0,0 +1,61 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// TestSolveStatsSaved 求解已保存的布局后统计保存到布局，修改布局后清除
+func TestSolveStatsSaved(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	game := saveGame(t, a, "stats", lineGame(5, 0, 4))
+	res := a.GameSolve(GameSolveReq{GameID: game.ID, Metric: utils.MetricStep, Force: true})
+	if !res.Success || res.Length != 4 || res.Stats.Generated == 0 || res.Stats.MaxDepth != 4 {
+		t.Fatalf("solve: %+v", res)
+	}
+
+	saved := models.Game{}
+	models.GetDB().First(&saved, game.ID)
+	stats := GameSolveStats{}
+	if err := json.Unmarshal([]byte(saved.SolveStats), &stats); err != nil {
+		t.Fatalf("saved stats %q: %v", saved.SolveStats, err)
+	}
+	if stats.Length != 4 || stats.Metric != utils.MetricStep || stats.Stats.Generated != res.Stats.Generated {
+		t.Errorf("saved stats %+v", stats)
+	}
+
+	// 改名不影响统计，修改布局后统计失效
+	saved.Name = "stats renamed"
+	if res := a.GameSave(saved); !res.Success || res.Game.SolveStats != saved.SolveStats {
+		t.Errorf("rename: %+v", res)
+	}
+	saved.GameShape = utils.GameData2GameShape(lineGame(5, 1, 4))
+	if res := a.GameSave(saved); !res.Success || res.Game.SolveStats != "" {
+		t.Errorf("layout change: %+v", res)
+	}
+	models.GetDB().First(&saved, game.ID)
+	if saved.SolveStats != "" {
+		t.Errorf("stats kept after the layout changed: %s", saved.SolveStats)
+	}
+}
+
+// TestSolveStatsPieceOrder 提交的布局与保存的布局棋子顺序不同时，仍然是同一个布局，统计照常保存
+func TestSolveStatsPieceOrder(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	game := saveGame(t, a, "stats piece order", kingSecondGame())
+	models.GetDB().Model(&models.Game{ID: game.ID}).Update("solve_stats", "")
+	res := a.GameSolve(GameSolveReq{GameID: game.ID, GameData: kingSecondGame(), Metric: utils.MetricStep, Force: true})
+	if !res.Success || res.Length != 1 {
+		t.Fatalf("solve: %+v", res)
+	}
+	saved := models.Game{}
+	models.GetDB().First(&saved, game.ID)
+	if saved.SolveStats == "" {
+		t.Error("stats not saved")
+	}
+}
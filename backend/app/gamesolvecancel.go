@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ErrJobRunning 前端指定的任务ID已经有求解在进行
+var ErrJobRunning = errors.New("job already running")
+
+type GameSolveCancelReq struct {
+	JobID int `json:"jobId"` // 要取消的任务，为0时取消所有求解
+}
+
+type GameSolveCancelRes struct {
+	Success bool `json:"success"`
+	Count   int  `json:"count"`
+}
+
+// SolveJobProgress 发送给前端的求解进度，带有所属任务的ID
+type SolveJobProgress struct {
+	JobID int `json:"jobId"`
+	utils.SolveProgress
+}
+
+// GameSolveCancel 取消指定的求解，不指定时取消所有正在进行的求解
+func (a *App) GameSolveCancel(req GameSolveCancelReq) GameSolveCancelRes {
+	a.solveMutex.Lock()
+	defer a.solveMutex.Unlock()
+	count := 0
+	for jobID, cancel := range a.solveCancels {
+		if req.JobID == 0 || req.JobID == jobID {
+			cancel()
+			count++
+		}
+	}
+	return GameSolveCancelRes{
+		Success: count > 0,
+		Count:   count,
+	}
+}
+
+// beginSolve 登记一次可取消的求解，求解结束后调用返回的函数注销
+// 前端指定的任务ID为正数；没有指定时分配负数ID，不会与前端的ID冲突
+func (a *App) beginSolve(jobID int) (int, context.Context, func(), error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.solveMutex.Lock()
+	defer a.solveMutex.Unlock()
+	if jobID <= 0 {
+		a.solveSeq++
+		jobID = -a.solveSeq
+	} else if _, ok := a.solveCancels[jobID]; ok {
+		cancel()
+		return jobID, ctx, func() {}, ErrJobRunning
+	}
+	a.solveCancels[jobID] = cancel
+	return jobID, ctx, func() {
+		cancel()
+		a.solveMutex.Lock()
+		delete(a.solveCancels, jobID)
+		a.solveMutex.Unlock()
+	}, nil
+}
+
+// solveErrMessage 求解没有完成时返回给前端的错误信息，其他错误使用 fallback
+func solveErrMessage(err error, fallback string) string {
+	if errors.Is(err, ErrSolverBusy) {
+		return "solverBusy"
+	}
+	if errors.Is(err, ErrJobRunning) {
+		return "jobAlreadyRunning"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "solveCancelled"
+	}
+	if errors.Is(err, utils.ErrLimitExceeded) {
+		return "limitExceeded"
+	}
+	if errors.Is(err, utils.ErrScratchDirBusy) {
+		return "scratchDirBusy"
+	}
+	return fallback
+}
+
+// solveProgress 把任务的求解进度发送给前端
+func (a *App) solveProgress(jobID int) func(utils.SolveProgress) {
+	return func(progress utils.SolveProgress) {
+		runtime.EventsEmit(a.ctx, "gameSolveProgress", SolveJobProgress{
+			JobID:         jobID,
+			SolveProgress: progress,
+		})
+	}
+}
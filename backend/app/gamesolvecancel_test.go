@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// TestSolveCancelJob 只取消指定的任务，不指定任务时取消全部
+func TestSolveCancelJob(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	_, ctx1, done1, err := a.beginSolve(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done1()
+	_, ctx2, done2, _ := a.beginSolve(2)
+	defer done2()
+	id3, ctx3, done3, _ := a.beginSolve(0)
+	defer done3()
+	id4, _, done4, _ := a.beginSolve(0)
+	defer done4()
+	if id3 >= 0 || id4 >= 0 || id3 == id4 {
+		t.Errorf("allocated job ids %d, %d", id3, id4)
+	}
+	if _, _, _, err := a.beginSolve(1); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("reused job id: %v", err)
+	}
+
+	if res := a.GameSolveCancel(GameSolveCancelReq{JobID: 1}); res.Count != 1 {
+		t.Errorf("cancelled %d jobs, want 1", res.Count)
+	}
+	if ctx1.Err() == nil || ctx2.Err() != nil || ctx3.Err() != nil {
+		t.Errorf("after cancelling job 1: %v, %v, %v", ctx1.Err(), ctx2.Err(), ctx3.Err())
+	}
+	if res := a.GameSolveCancel(GameSolveCancelReq{JobID: 5}); res.Success {
+		t.Error("cancelled an unknown job")
+	}
+	if res := a.GameSolveCancel(GameSolveCancelReq{}); res.Count != 4 {
+		t.Errorf("cancelled %d jobs, want 4", res.Count)
+	}
+	if ctx2.Err() == nil || ctx3.Err() == nil {
+		t.Error("cancel all left jobs running")
+	}
+}
+
+// TestSolveJobID 结果带有任务ID，同一ID的任务正在进行时拒绝新的求解
+func TestSolveJobID(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	game := lineGame(3, 0, 2)
+	res := a.GameSolve(GameSolveReq{JobID: 7, GameData: game, Force: true})
+	if !res.Success || res.JobID != 7 || res.Length != 1 {
+		t.Errorf("got %+v", res)
+	}
+
+	_, _, done, _ := a.beginSolve(7)
+	defer done()
+	res = a.GameSolve(GameSolveReq{JobID: 7, GameData: game, Force: true})
+	if res.Success || res.ErrMessage != "jobAlreadyRunning" || res.JobID != 7 {
+		t.Errorf("got %+v", res)
+	}
+}
+
+func TestSolveErrMessage(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{ErrSolverBusy, "solverBusy"},
+		{ErrJobRunning, "jobAlreadyRunning"},
+		{context.Canceled, "solveCancelled"},
+		{&utils.LimitError{Limit: utils.LimitStates}, "limitExceeded"},
+		{utils.ErrScratchDirBusy, "scratchDirBusy"},
+		{errors.New("no solution"), "noSolution"},
+	}
+	for _, c := range cases {
+		if got := solveErrMessage(c.err, "noSolution"); got != c.want {
+			t.Errorf("%v: got %s, want %s", c.err, got, c.want)
+		}
+	}
+}
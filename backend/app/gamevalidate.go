@@ -0,0 +1,45 @@
+package app
+
+import "github.com/addlete/custom-klotski/backend/utils"
+
+type GameValidateReq struct {
+	GameID   uint           `json:"gameId"` // 已保存的布局的ID，GameData 为空时使用已保存的布局
+	GameData utils.GameData `json:"gameData"`
+}
+
+type GameValidateRes struct {
+	Success          bool                    `json:"success"`
+	ErrMessage       string                  `json:"errMessage"`
+	GameData         utils.GameData          `json:"gameData"`
+	GameShape        string                  `json:"gameShape"` // 保存到数据库的布局数据
+	Goal             string                  `json:"goal"`      // 保存到数据库的获胜条件，棋子索引与 GameShape 相同
+	Md5              string                  `json:"md5"`
+	ValidationErrors []utils.ValidationError `json:"validationErrors"`
+}
+
+// GameValidate 检查布局，返回用于显示的布局和保存用的布局数据
+// 通过检查时返回的布局按布局数据的顺序排列棋子，王棋在最前面，获胜条件随之调整
+func (a *App) GameValidate(req GameValidateReq) GameValidateRes {
+	if errMessage := loadGameData(req.GameID, &req.GameData); errMessage != "" {
+		return GameValidateRes{
+			Success:    false,
+			ErrMessage: errMessage,
+		}
+	}
+	if errs := utils.Validate(req.GameData); len(errs) > 0 {
+		return GameValidateRes{
+			Success:          false,
+			ErrMessage:       "invalidGameData",
+			GameData:         req.GameData,
+			ValidationErrors: errs,
+		}
+	}
+	gameData := utils.KingFirst(req.GameData)
+	return GameValidateRes{
+		Success:   true,
+		GameData:  gameData,
+		GameShape: utils.GameData2GameShape(gameData),
+		Goal:      utils.GameData2Goal(gameData),
+		Md5:       utils.PuzzleMd5(gameData),
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import "github.com/addlete/custom-klotski/backend/utils"
+
+type GameVerifySolutionReq struct {
+	GameID   uint           `json:"gameId"` // 已保存的布局的ID，GameData 为空时使用已保存的布局
+	GameData utils.GameData `json:"gameData"`
+	Solution []utils.Step   `json:"solution"`
+}
+
+type GameVerifySolutionRes struct {
+	Success          bool                    `json:"success"`
+	ErrMessage       string                  `json:"errMessage"`
+	Result           utils.VerifyResult      `json:"result"`
+	ValidationErrors []utils.ValidationError `json:"validationErrors"`
+}
+
+// GameVerifySolution 检查一个解法能否从开局走到获胜
+func (a *App) GameVerifySolution(req GameVerifySolutionReq) GameVerifySolutionRes {
+	if errMessage := loadGameData(req.GameID, &req.GameData); errMessage != "" {
+		return GameVerifySolutionRes{
+			Success:    false,
+			ErrMessage: errMessage,
+		}
+	}
+	if errs := utils.Validate(req.GameData); len(errs) > 0 {
+		return GameVerifySolutionRes{
+			Success:          false,
+			ErrMessage:       "invalidGameData",
+			ValidationErrors: errs,
+		}
+	}
+	result, err := utils.Verify(req.GameData, req.Solution)
+	if err != nil {
+		return GameVerifySolutionRes{
+			Success:    false,
+			ErrMessage: "invalidGameData",
+		}
+	}
+	if !result.Valid {
+		return GameVerifySolutionRes{
+			Success:    false,
+			ErrMessage: "invalidSolutionStep",
+			Result:     result,
+		}
+	}
+	if !result.Solved {
+		return GameVerifySolutionRes{
+			Success:    false,
+			ErrMessage: "solutionNotSolved",
+			Result:     result,
+		}
+	}
+	return GameVerifySolutionRes{
+		Success: true,
+		Result:  result,
+	}
+}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/models"
+)
+
+// TestMain 测试使用临时目录中的数据库，不改动用户的数据
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "custom-klotski-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(models.DBFileEnv, filepath.Join(dir, "data.db"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,20 @@
+package app
+
+import "github.com/addlete/custom-klotski/backend/utils"
+
+// PlayMove 走一步，按求解器的规则检查这一步能否走
+func (a *App) PlayMove(step utils.Step) PlaySessionRes {
+	a.playMutex.Lock()
+	defer a.playMutex.Unlock()
+	if a.playSession == nil {
+		return a.sessionRes("")
+	}
+	if !a.playSession.winTime.IsZero() {
+		return a.sessionRes("gameAlreadyWon")
+	}
+	if !a.playSession.move(step) {
+		return a.sessionRes("invalidMove")
+	}
+	a.playSession.redoList = nil
+	return a.sessionRes("")
+}
@@ -0,0 +1,18 @@
+package app
+
+// PlayRedo 重做上一次悔棋撤销的步
+func (a *App) PlayRedo() PlaySessionRes {
+	a.playMutex.Lock()
+	defer a.playMutex.Unlock()
+	session := a.playSession
+	if session == nil {
+		return a.sessionRes("")
+	}
+	if len(session.redoList) == 0 {
+		return a.sessionRes("nothingToRedo")
+	}
+	move := session.redoList[len(session.redoList)-1]
+	session.redoList = session.redoList[:len(session.redoList)-1]
+	session.move(move.step)
+	return a.sessionRes("")
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"time"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// PlaySession 一局试玩，走法和胜利判断都由求解器的规则完成
+type PlaySession struct {
+	gameID    uint
+	gameSolve *utils.GameSolve
+	undoList  []playMove // 已经走过的步，可以悔棋
+	redoList  []playMove // 悔棋撤销的步，可以重做，走新的一步时清空
+	startTime time.Time
+	winTime   time.Time // 获胜的时间，未获胜时为零值
+}
+
+// playMove 走过的一步，以及走之前的局面
+type playMove struct {
+	step   utils.Step
+	before []utils.Pos
+}
+
+// PlaySessionState 返回给前端的试玩状态
+type PlaySessionState struct {
+	GameID    uint         `json:"gameId"`
+	Metric    utils.Metric `json:"metric"`
+	Positions []utils.Pos  `json:"positions"` // 每个棋子当前的位置
+	Moves     int          `json:"moves"`     // 已经走的步数，与 utils.Verify 相同，同一棋子连续移动算一步
+	Steps     []utils.Step `json:"steps"`     // 已经走过的步
+	CanUndo   bool         `json:"canUndo"`
+	CanRedo   bool         `json:"canRedo"`
+	Elapsed   int64        `json:"elapsed"` // 已经用去的时间，毫秒，获胜后不再增加
+	Win       bool         `json:"win"`
+}
+
+type PlaySessionRes struct {
+	Success    bool             `json:"success"`
+	ErrMessage string           `json:"errMessage"`
+	Session    PlaySessionState `json:"session"`
+}
+
+func (s *PlaySession) state() PlaySessionState {
+	state := PlaySessionState{
+		GameID:    s.gameID,
+		Metric:    s.gameSolve.Options.Metric,
+		Positions: s.gameSolve.Positions(),
+		Steps:     []utils.Step{},
+		CanUndo:   len(s.undoList) > 0,
+		CanRedo:   len(s.redoList) > 0,
+		Win:       !s.winTime.IsZero(),
+	}
+	for _, move := range s.undoList {
+		state.Steps = append(state.Steps, move.step)
+	}
+	state.Moves = utils.CountMoves(state.Steps)
+	if state.Win {
+		state.Elapsed = s.winTime.Sub(s.startTime).Milliseconds()
+	} else {
+		state.Elapsed = time.Since(s.startTime).Milliseconds()
+	}
+	return state
+}
+
+// move 走一步，走不了时返回false
+func (s *PlaySession) move(step utils.Step) bool {
+	before := s.gameSolve.Positions()
+	if !s.gameSolve.TryStep(step) {
+		return false
+	}
+	s.undoList = append(s.undoList, playMove{step: step, before: before})
+	s.checkWin()
+	return true
+}
+
+func (s *PlaySession) checkWin() {
+	if s.gameSolve.IsWin() {
+		s.winTime = time.Now()
+	} else {
+		s.winTime = time.Time{}
+	}
+}
+
+// sessionRes 当前试玩的状态，没有开始试玩时返回错误
+func (a *App) sessionRes(errMessage string) PlaySessionRes {
+	if a.playSession == nil {
+		return PlaySessionRes{
+			Success:    false,
+			ErrMessage: "noPlaySession",
+		}
+	}
+	return PlaySessionRes{
+		Success:    errMessage == "",
+		ErrMessage: errMessage,
+		Session:    a.playSession.state(),
+	}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// saveGame 保存布局供试玩使用
+func saveGame(t *testing.T, a *App, name string, game utils.GameData) models.Game {
+	t.Helper()
+	res := a.GameSave(models.Game{
+		Name:     name,
+		GameData: &game,
+	})
+	if !res.Success && res.ErrMessage != "gameAlreadyExists" {
+		t.Fatalf("save %s: %s %v", name, res.ErrMessage, res.ValidationErrors)
+	}
+	return res.Game
+}
+
+// TestPlaySession 走法按求解器的规则检查，悔棋和重做恢复局面，走新的一步清空重做
+func TestPlaySession(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	if res := a.PlayState(); res.Success || res.ErrMessage != "noPlaySession" {
+		t.Errorf("no session: %+v", res)
+	}
+	game := saveGame(t, a, "play", lineGame(4, 0, 3))
+	if res := a.PlayStart(PlayStartReq{GameID: 0}); res.ErrMessage != "gameNotFound" {
+		t.Errorf("unknown game: %+v", res)
+	}
+	res := a.PlayStart(PlayStartReq{GameID: game.ID})
+	if !res.Success || res.Session.Moves != 0 || res.Session.Win || res.Session.Metric != utils.MetricPiece {
+		t.Fatalf("start: %+v", res)
+	}
+	at := func(res PlaySessionRes, col int16) bool {
+		positions := res.Session.Positions
+		return len(positions) == 1 && positions[0][0] == 0 && positions[0][1] == col
+	}
+
+	right := func(cols int16) utils.Step {
+		return utils.Step{PieceIndex: 0, Direction: []int16{0, cols}}
+	}
+	if res := a.PlayMove(right(4)); res.ErrMessage != "invalidMove" || !at(res, 0) {
+		t.Errorf("move outside the board: %+v", res)
+	}
+	if res := a.PlayUndo(); res.ErrMessage != "nothingToUndo" {
+		t.Errorf("undo at start: %+v", res)
+	}
+	if res := a.PlayMove(right(1)); !res.Success || !at(res, 1) || res.Session.Moves != 1 || !res.Session.CanUndo {
+		t.Errorf("move: %+v", res)
+	}
+	if res := a.PlayUndo(); !res.Success || !at(res, 0) || res.Session.Moves != 0 || !res.Session.CanRedo {
+		t.Errorf("undo: %+v", res)
+	}
+	if res := a.PlayRedo(); !res.Success || !at(res, 1) || res.Session.Moves != 1 || res.Session.CanRedo {
+		t.Errorf("redo: %+v", res)
+	}
+	if res := a.PlayRedo(); res.ErrMessage != "nothingToRedo" {
+		t.Errorf("redo with nothing undone: %+v", res)
+	}
+
+	// 悔棋之后走新的一步，按棋子计步一步可以连走两格
+	a.PlayUndo()
+	res = a.PlayMove(right(3))
+	if !res.Success || !at(res, 3) || !res.Session.Win || res.Session.CanRedo || len(res.Session.Steps) != 1 {
+		t.Errorf("winning move: %+v", res)
+	}
+	if res := a.PlayMove(right(-1)); res.ErrMessage != "gameAlreadyWon" {
+		t.Errorf("move after winning: %+v", res)
+	}
+	if res := a.PlayUndo(); !res.Success || res.Session.Win {
+		t.Errorf("undo the winning move: %+v", res)
+	}
+
+	// 同一棋子连续走两次算一步，与检查解法的计步相同
+	a.PlayMove(right(1))
+	res = a.PlayMove(right(1))
+	verify, err := utils.Verify(lineGame(4, 0, 3), res.Session.Steps)
+	if !res.Success || len(res.Session.Steps) != 2 || res.Session.Moves != 1 || err != nil || verify.Moves != res.Session.Moves {
+		t.Errorf("moves %d, verified %d, %v", res.Session.Moves, verify.Moves, err)
+	}
+}
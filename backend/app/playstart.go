@@ -0,0 +1,52 @@
+package app
+
+import (
+	"time"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+type PlayStartReq struct {
+	GameID uint         `json:"gameId"`
+	Metric utils.Metric `json:"metric"`
+}
+
+// PlayStart 开始试玩一个已保存的布局，之前的试玩会被丢弃
+func (a *App) PlayStart(req PlayStartReq) PlaySessionRes {
+	game := models.Game{}
+	models.GetDB().First(&game, req.GameID)
+	if game.ID == 0 {
+		return PlaySessionRes{
+			Success:    false,
+			ErrMessage: "gameNotFound",
+		}
+	}
+	gameData, err := gameDataOf(game)
+	if err != nil || len(utils.Validate(gameData)) > 0 {
+		return PlaySessionRes{
+			Success:    false,
+			ErrMessage: "invalidGameData",
+		}
+	}
+	metric := req.Metric
+	if metric == "" {
+		metric = utils.MetricPiece
+	}
+	gameSolve := &utils.GameSolve{
+		Options: utils.SolveOptions{
+			Metric: metric,
+		},
+	}
+	gameSolve.Init(gameData)
+
+	a.playMutex.Lock()
+	defer a.playMutex.Unlock()
+	a.playSession = &PlaySession{
+		gameID:    game.ID,
+		gameSolve: gameSolve,
+		startTime: time.Now(),
+	}
+	a.playSession.checkWin()
+	return a.sessionRes("")
+}
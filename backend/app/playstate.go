@@ -0,0 +1,8 @@
+package app
+
+// PlayState 当前试玩的状态
+func (a *App) PlayState() PlaySessionRes {
+	a.playMutex.Lock()
+	defer a.playMutex.Unlock()
+	return a.sessionRes("")
+}
@@ -0,0 +1,20 @@
+package app
+
+// PlayUndo 悔棋，退回上一步之前的局面
+func (a *App) PlayUndo() PlaySessionRes {
+	a.playMutex.Lock()
+	defer a.playMutex.Unlock()
+	session := a.playSession
+	if session == nil {
+		return a.sessionRes("")
+	}
+	if len(session.undoList) == 0 {
+		return a.sessionRes("nothingToUndo")
+	}
+	move := session.undoList[len(session.undoList)-1]
+	session.undoList = session.undoList[:len(session.undoList)-1]
+	session.gameSolve.SetPositions(move.before)
+	session.redoList = append(session.redoList, move)
+	session.checkWin()
+	return a.sessionRes("")
+}
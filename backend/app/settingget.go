@@ -0,0 +1,57 @@
+package app
+
+import (
+	"math"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+type SettingGetRes struct {
+	Success    bool           `json:"success"`
+	ErrMessage string         `json:"errMessage"`
+	Setting    models.Setting `json:"setting"`
+}
+
+func (a *App) SettingGet() SettingGetRes {
+	return SettingGetRes{
+		Success: true,
+		Setting: loadSetting(),
+	}
+}
+
+// loadSetting 读取设置，没有保存过时返回默认值
+func loadSetting() models.Setting {
+	setting := models.Setting{}
+	db := models.GetDB()
+	if err := db.First(&setting, models.DefaultSetting.ID).Error; err != nil {
+		return models.DefaultSetting
+	}
+	return setting
+}
+
+// applyLimits 求解选项中的限制为0时使用设置中的值，为负数（前端传-1）时本次求解不限制，
+// 设置了全局限制之后单次求解仍然可以不受限制
+func applyLimits(options *utils.SolveOptions) {
+	setting := loadSetting()
+	switch {
+	case options.MaxStates < 0:
+		// 局面编号为int32，不限制时以此为上限
+		options.MaxStates = math.MaxInt32
+	case options.MaxStates == 0:
+		options.MaxStates = setting.MaxStates
+	}
+	options.MaxTime = limitOrSetting(options.MaxTime, setting.MaxTime)
+	options.MaxMemory = limitOrSetting(options.MaxMemory, setting.MaxMemory)
+}
+
+// limitOrSetting 用时和内存的限制，为0时使用设置中的值，为负数时返回0即不限制
+func limitOrSetting(limit int, setting int) int {
+	if limit < 0 {
+		return 0
+	}
+	if limit == 0 {
+		return setting
+	}
+	return limit
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"math"
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// TestApplyLimits 没有指定的限制使用设置中的值，指定为-1时本次求解不限制
+func TestApplyLimits(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	defer a.SettingSave(loadSetting())
+	if res := a.SettingSave(models.Setting{MaxStates: 1000, MaxTime: 10, MaxMemory: 100}); !res.Success {
+		t.Fatalf("save setting: %+v", res)
+	}
+
+	cases := []struct {
+		name  string
+		given utils.SolveOptions
+		want  utils.SolveOptions
+	}{
+		{"not given", utils.SolveOptions{}, utils.SolveOptions{MaxStates: 1000, MaxTime: 10, MaxMemory: 100}},
+		{"given", utils.SolveOptions{MaxStates: 50, MaxTime: 2, MaxMemory: 8}, utils.SolveOptions{MaxStates: 50, MaxTime: 2, MaxMemory: 8}},
+		{"unlimited", utils.SolveOptions{MaxStates: -1, MaxTime: -1, MaxMemory: -1}, utils.SolveOptions{MaxStates: math.MaxInt32}},
+	}
+	for _, c := range cases {
+		options := c.given
+		applyLimits(&options)
+		if options.MaxStates != c.want.MaxStates || options.MaxTime != c.want.MaxTime || options.MaxMemory != c.want.MaxMemory {
+			t.Errorf("%s: got %d/%d/%d", c.name, options.MaxStates, options.MaxTime, options.MaxMemory)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package app
+
+import "github.com/addlete/custom-klotski/backend/models"
+
+type SettingSaveRes struct {
+	Success    bool           `json:"success"`
+	ErrMessage string         `json:"errMessage"`
+	Setting    models.Setting `json:"setting"`
+}
+
+func (a *App) SettingSave(setting models.Setting) SettingSaveRes {
+	if setting.MaxStates < 0 || setting.MaxTime < 0 || setting.MaxMemory < 0 {
+		return SettingSaveRes{
+			Success:    false,
+			ErrMessage: "invalidSetting",
+		}
+	}
+	setting.ID = models.DefaultSetting.ID
+	db := models.GetDB()
+	if err := db.Save(&setting).Error; err != nil {
+		return SettingSaveRes{
+			Success:    false,
+			ErrMessage: "failedToSaveSetting",
+		}
+	}
+	return SettingSaveRes{
+		Success: true,
+		Setting: setting,
+	}
+}
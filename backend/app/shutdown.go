@@ -0,0 +1,11 @@
+package app
+
+import (
+	"context"
+)
+
+// ShutDown 退出前取消所有求解，等待工作池中的任务结束
+func (a *App) ShutDown(ctx context.Context) {
+	a.GameSolveCancel(GameSolveCancelReq{})
+	a.solver.Close()
+}
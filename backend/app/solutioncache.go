@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/addlete/custom-klotski/backend/models"
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// 求解结果缓存在数据库中，按布局的规范摘要和计步方式查找，求解器版本不同的结果视为没有缓存
+
+// loadSolution 取出缓存的解法，并换成这份布局数据中的棋子索引
+func loadSolution(gameData utils.GameData, metric utils.Metric) (utils.SolveResult, bool) {
+	result := utils.SolveResult{}
+	solution := models.Solution{}
+	db := models.GetDB()
+	db.Where("puzzle_hash = ? AND metric = ? AND solver_version = ?",
+		utils.PuzzleHash(gameData), string(metric), utils.SolverVersion).Limit(1).Find(&solution)
+	if solution.ID == 0 {
+		return result, false
+	}
+	var cellSteps []utils.CellStep
+	if err := json.Unmarshal([]byte(solution.Steps), &cellSteps); err != nil {
+		return result, false
+	}
+	steps, err := utils.CellsToSteps(gameData, cellSteps)
+	if err != nil {
+		return result, false
+	}
+	_ = json.Unmarshal([]byte(solution.Stats), &result.Stats)
+	result.Steps = steps
+	result.Metric = metric
+	result.Algorithm = utils.Algorithm(solution.Algorithm)
+	result.Length = solution.Length
+	result.Optimal = true
+	return result, true
+}
+
+// storeSolution 缓存最优解，替换同一布局和计步方式之前缓存的结果
+func storeSolution(gameData utils.GameData, result utils.SolveResult) {
+	if !result.Optimal {
+		return
+	}
+	cellSteps, err := utils.StepsToCells(gameData, result.Steps)
+	if err != nil {
+		return
+	}
+	steps, _ := json.Marshal(cellSteps)
+	stats, _ := json.Marshal(result.Stats)
+	solution := models.Solution{
+		PuzzleHash:    utils.PuzzleHash(gameData),
+		Metric:        string(result.Metric),
+		SolverVersion: utils.SolverVersion,
+		Algorithm:     string(result.Algorithm),
+		Length:        result.Length,
+		Steps:         string(steps),
+		Stats:         string(stats),
+	}
+	db := models.GetDB()
+	db.Where("puzzle_hash = ? AND metric = ?", solution.PuzzleHash, solution.Metric).Delete(&models.Solution{})
+	db.Create(&solution)
+}
+
+// forgetSolutions 删除布局所有计步方式的缓存
+func forgetSolutions(gameData utils.GameData) {
+	db := models.GetDB()
+	db.Where("puzzle_hash = ?", utils.PuzzleHash(gameData)).Delete(&models.Solution{})
+}
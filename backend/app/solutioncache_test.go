@@ -0,0 +1,51 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// TestSolutionCache 同一布局第二次求解使用缓存，棋子顺序不同的同一布局也能命中并正确重放
+func TestSolutionCache(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	game := lineGame(6, 0, 5)
+	game.PieceList = append(game.PieceList, utils.Piece{Shape: utils.Shape{{true}}, Position: utils.Pos{0, 3}})
+	game.Goal.Pieces[0].Position = utils.Pos{0, 2}
+	forgetSolutions(game)
+
+	first := a.GameSolve(GameSolveReq{GameData: game, Metric: utils.MetricStep})
+	if !first.Success || first.Cached || first.Length != 2 {
+		t.Fatalf("first solve: %+v", first)
+	}
+	second := a.GameSolve(GameSolveReq{GameData: game, Metric: utils.MetricStep})
+	if !second.Success || !second.Cached || second.Length != 2 || second.Stats.Generated != first.Stats.Generated {
+		t.Errorf("second solve: %+v", second)
+	}
+	if res := a.GameSolve(GameSolveReq{GameData: game, Metric: utils.MetricPiece}); res.Cached {
+		t.Error("another metric used the cache")
+	}
+	if res := a.GameSolve(GameSolveReq{GameData: game, Metric: utils.MetricStep, Force: true}); res.Cached || !res.Success {
+		t.Errorf("forced solve: %+v", res)
+	}
+
+	// 棋子顺序相反，目标棋子的索引随之改变
+	reordered := game
+	reordered.PieceList = []utils.Piece{game.PieceList[1], game.PieceList[0]}
+	reordered.KingPieceIndex = 1
+	reordered.Goal = &utils.Goal{Pieces: []utils.PieceGoal{{PieceIndex: 1, Position: utils.Pos{0, 2}}}}
+	res := a.GameSolve(GameSolveReq{GameData: reordered, Metric: utils.MetricStep})
+	if !res.Success || !res.Cached {
+		t.Fatalf("reordered layout: %+v", res)
+	}
+	verify, err := utils.Verify(reordered, res.Solution)
+	if err != nil || !verify.Solved {
+		t.Errorf("cached solution does not verify on the reordered layout: %+v", res.Solution)
+	}
+
+	forgetSolutions(game)
+	if res := a.GameSolve(GameSolveReq{GameData: game, Metric: utils.MetricStep}); res.Cached {
+		t.Error("cache kept after forgetting")
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// solveJob 一次用到求解器的请求，求解、分析、提示等共用同样的准备步骤：
+// 分配任务ID，读取已保存的布局，检查布局，按设置补全限制，进度按任务ID发给前端
+type solveJob struct {
+	app              *App
+	id               int
+	ctx              context.Context
+	done             func()
+	gameData         utils.GameData
+	errMessage       string // 准备失败的原因，为空时可以开始求解
+	validationErrors []utils.ValidationError
+}
+
+// startSolveJob 开始一个求解任务，gameData 为空时读取已保存的布局
+// 无论是否准备成功都需要调用 finish
+func (a *App) startSolveJob(jobID int, gameID uint, gameData utils.GameData) *solveJob {
+	job := &solveJob{app: a, gameData: gameData}
+	var err error
+	job.id, job.ctx, job.done, err = a.beginSolve(jobID)
+	if err != nil {
+		job.errMessage = solveErrMessage(err, "")
+		return job
+	}
+	if errMessage := loadGameData(gameID, &job.gameData); errMessage != "" {
+		job.errMessage = errMessage
+		return job
+	}
+	if errs := utils.Validate(job.gameData); len(errs) > 0 {
+		job.errMessage = "invalidGameData"
+		job.validationErrors = errs
+	}
+	return job
+}
+
+// finish 结束任务，把任务ID写入返回给前端的结果，需要在设置返回值之后调用，一般用 defer
+func (job *solveJob) finish(resJobID *int) {
+	*resJobID = job.id
+	job.done()
+}
+
+// newGameSolve 创建求解器，没有指定的限制使用设置中的值；game 一般为 job.gameData
+func (job *solveJob) newGameSolve(options utils.SolveOptions, game utils.GameData) *utils.GameSolve {
+	gameSolve := &utils.GameSolve{
+		Options:    options,
+		OnProgress: job.app.solveProgress(job.id),
+	}
+	applyLimits(&gameSolve.Options)
+	gameSolve.Init(game)
+	return gameSolve
+}
+
+// run 在求解服务中执行，取消任务时 ctx 随之取消
+func (job *solveJob) run(fn func(ctx context.Context) error) error {
+	return job.app.solver.Do(job.ctx, fn)
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+// TestSolveJobPrepare 各个求解接口的准备步骤相同：布局不存在或检查不通过时返回同样的错误和任务ID
+func TestSolveJobPrepare(t *testing.T) {
+	a := NewApp()
+	defer a.solver.Close()
+	invalid := lineGame(3, 0, 2)
+	invalid.Goal.Pieces[0].PieceIndex = 3
+
+	type result struct {
+		errMessage string
+		jobID      int
+		errs       []utils.ValidationError
+	}
+	calls := map[string]func(jobID int, gameID uint, game utils.GameData) result{
+		"solve": func(jobID int, gameID uint, game utils.GameData) result {
+			res := a.GameSolve(GameSolveReq{JobID: jobID, GameID: gameID, GameData: game})
+			return result{res.ErrMessage, res.JobID, res.ValidationErrors}
+		},
+		"analyze": func(jobID int, gameID uint, game utils.GameData) result {
+			res := a.GameAnalyze(GameAnalyzeReq{JobID: jobID, GameID: gameID, GameData: game})
+			return result{res.ErrMessage, res.JobID, res.ValidationErrors}
+		},
+		"hardest": func(jobID int, gameID uint, game utils.GameData) result {
+			res := a.GameHardest(GameHardestReq{JobID: jobID, GameID: gameID, GameData: game})
+			return result{res.ErrMessage, res.JobID, res.ValidationErrors}
+		},
+		"count solutions": func(jobID int, gameID uint, game utils.GameData) result {
+			res := a.GameCountSolutions(GameCountSolutionsReq{JobID: jobID, GameID: gameID, GameData: game})
+			return result{res.ErrMessage, res.JobID, res.ValidationErrors}
+		},
+		"hint": func(jobID int, gameID uint, game utils.GameData) result {
+			res := a.GameHint(GameHintReq{JobID: jobID, GameID: gameID, GameData: game})
+			return result{res.ErrMessage, res.JobID, res.ValidationErrors}
+		},
+	}
+	for name, call := range calls {
+		if res := call(7, 0, invalid); res.errMessage != "invalidGameData" || res.jobID != 7 || len(res.errs) == 0 {
+			t.Errorf("%s, invalid layout: %+v", name, res)
+		}
+		if res := call(0, 1<<30, utils.GameData{}); res.errMessage != "gameNotFound" || res.jobID >= 0 {
+			t.Errorf("%s, unknown game: %+v", name, res)
+		}
+	}
+}
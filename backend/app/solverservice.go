@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSolverBusy 排队的任务已满
+var ErrSolverBusy = errors.New("solver busy")
+
+// ErrSolverClosed 工作池已经关闭
+var ErrSolverClosed = errors.New("solver closed")
+
+const (
+	defaultSolverWorkers = 2  // 同时运行的求解任务数
+	defaultSolverQueue   = 16 // 最多排队等待的任务数
+)
+
+// 任务的状态
+const (
+	jobQueued    int32 = iota // 排队中
+	jobStarted                // 已经开始运行
+	jobAbandoned              // 排队时调用方已经取消，不再运行
+)
+
+// SolverService 求解任务的工作池
+// 同时运行的任务数有上限，多出的任务在队列中等待，后台分析和交互中的提示等可以同时提交
+type SolverService struct {
+	jobs    chan *solverJob
+	workers int
+	mutex   sync.Mutex // 保护关闭队列
+	closed  bool
+	running int32 // 正在运行的任务数
+	queued  int32 // 排队中的任务数
+	wg      sync.WaitGroup
+}
+
+type solverJob struct {
+	ctx   context.Context
+	run   func(ctx context.Context) error
+	state int32
+	err   error
+	done  chan struct{}
+}
+
+// SolverStatus 工作池的状态
+type SolverStatus struct {
+	Workers int `json:"workers"`
+	Running int `json:"running"`
+	Queued  int `json:"queued"`
+}
+
+// NewSolverService 创建工作池并启动workers个协程，queueSize为最多排队的任务数
+func NewSolverService(workers int, queueSize int) *SolverService {
+	if workers <= 0 {
+		workers = defaultSolverWorkers
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	s := &SolverService{
+		jobs:    make(chan *solverJob, queueSize),
+		workers: workers,
+	}
+	s.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+func (s *SolverService) work() {
+	defer s.wg.Done()
+	for job := range s.jobs {
+		atomic.AddInt32(&s.queued, -1)
+		if atomic.CompareAndSwapInt32(&job.state, jobQueued, jobStarted) {
+			atomic.AddInt32(&s.running, 1)
+			job.err = job.run(job.ctx)
+			atomic.AddInt32(&s.running, -1)
+			close(job.done)
+		}
+	}
+}
+
+// Do 把任务加入队列并等待它运行完毕，返回任务的错误
+// 队列已满时立即返回 ErrSolverBusy；排队时ctx被取消则放弃任务并返回ctx的错误，
+// 已经开始运行的任务需要自己响应ctx的取消
+func (s *SolverService) Do(ctx context.Context, run func(ctx context.Context) error) error {
+	job := &solverJob{
+		ctx:  ctx,
+		run:  run,
+		done: make(chan struct{}),
+	}
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return ErrSolverClosed
+	}
+	atomic.AddInt32(&s.queued, 1)
+	select {
+	case s.jobs <- job:
+	default:
+		atomic.AddInt32(&s.queued, -1)
+		s.mutex.Unlock()
+		return ErrSolverBusy
+	}
+	s.mutex.Unlock()
+
+	select {
+	case <-job.done:
+		return job.err
+	case <-ctx.Done():
+		if atomic.CompareAndSwapInt32(&job.state, jobQueued, jobAbandoned) {
+			return ctx.Err()
+		}
+		<-job.done
+		return job.err
+	}
+}
+
+// Status 工作池当前的状态
+func (s *SolverService) Status() SolverStatus {
+	return SolverStatus{
+		Workers: s.workers,
+		Running: int(atomic.LoadInt32(&s.running)),
+		Queued:  int(atomic.LoadInt32(&s.queued)),
+	}
+}
+
+// Close 不再接受新任务，等待已经排队的任务处理完毕
+func (s *SolverService) Close() {
+	s.mutex.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.jobs)
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/addlete/custom-klotski/backend/utils"
+)
+
+func TestSolverServiceBoundsWorkers(t *testing.T) {
+	s := NewSolverService(2, 16)
+	defer s.Close()
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Do(context.Background(), func(ctx context.Context) error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxRunning > 2 {
+		t.Errorf("%d jobs ran at once, want at most 2", maxRunning)
+	}
+}
+
+func TestSolverServiceBusy(t *testing.T) {
+	s := NewSolverService(1, 1)
+	defer s.Close()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go s.Do(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	queued := make(chan error)
+	go func() {
+		queued <- s.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+	}()
+	// 等第二个任务进入队列
+	for s.Status().Queued == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	err := s.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	if !errors.Is(err, ErrSolverBusy) {
+		t.Errorf("got %v, want ErrSolverBusy", err)
+	}
+	close(release)
+	if err := <-queued; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSolverServiceCancelQueued(t *testing.T) {
+	s := NewSolverService(1, 4)
+	defer s.Close()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go s.Do(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := int32(0)
+	done := make(chan error)
+	go func() {
+		done <- s.Do(ctx, func(ctx context.Context) error {
+			atomic.StoreInt32(&ran, 1)
+			return nil
+		})
+	}()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	close(release)
+	s.Close()
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Error("cancelled job ran")
+	}
+}
+
+// TestSolverServiceConcurrentSolves 后台分析和求解同时在工作池中运行
+func TestSolverServiceConcurrentSolves(t *testing.T) {
+	s := NewSolverService(3, 8)
+	defer s.Close()
+	v := utils.Shape{{true}, {true}}
+	h := utils.Shape{{true, true}}
+	sq := utils.Shape{{true}}
+	k := utils.Shape{{true, true}, {true, true}}
+	piece := func(shape utils.Shape, row int16, col int16) utils.Piece {
+		return utils.Piece{Shape: shape, Position: utils.Pos{row, col}}
+	}
+	game := utils.GameData{
+		BoardRows:      5,
+		BoardCols:      4,
+		KingPieceIndex: 0,
+		KingWinPos:     utils.Pos{3, 1},
+		Door:           utils.Door{Placement: "bottom", StartIndex: 1, XSize: 2, YSize: 2},
+		PieceList: []utils.Piece{
+			piece(k, 0, 1), piece(v, 0, 0), piece(v, 0, 3), piece(v, 2, 0), piece(v, 2, 3),
+			piece(h, 2, 1), piece(sq, 3, 1), piece(sq, 3, 2), piece(sq, 4, 0), piece(sq, 4, 3),
+		},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			gs := utils.GameSolve{Options: utils.SolveOptions{Metric: utils.MetricPiece}}
+			gs.Init(game)
+			var analysis utils.AnalyzeResult
+			err := s.Do(context.Background(), func(ctx context.Context) error {
+				var err error
+				analysis, err = gs.Analyze(ctx)
+				return err
+			})
+			if err != nil || analysis.StartDistance != 81 {
+				t.Errorf("analyze: %d %v", analysis.StartDistance, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			gs := utils.GameSolve{Options: utils.SolveOptions{Metric: utils.MetricStep}}
+			gs.Init(game)
+			var result utils.SolveResult
+			err := s.Do(context.Background(), func(ctx context.Context) error {
+				var err error
+				result, err = gs.Solve(ctx)
+				return err
+			})
+			if err != nil || result.Length != 116 {
+				t.Errorf("solve: %d %v", result.Length, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,14 @@
+package app
+
+type SolverStatusRes struct {
+	Success bool         `json:"success"`
+	Status  SolverStatus `json:"status"`
+}
+
+// SolverStatus 求解工作池中正在运行和排队的任务数
+func (a *App) SolverStatus() SolverStatusRes {
+	return SolverStatusRes{
+		Success: true,
+		Status:  a.solver.Status(),
+	}
+}
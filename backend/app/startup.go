@@ -6,4 +6,5 @@ import (
 
 func (a *App) StartUp(ctx context.Context) {
 	a.ctx = ctx
+	rehashGames()
 }
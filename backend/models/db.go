@@ -11,14 +11,21 @@ import (
 var db *gorm.DB
 var once sync.Once
 
+// DBFileEnv 指定数据库文件的环境变量，为空时使用文档目录下的数据库，测试时指向临时文件
+const DBFileEnv = "CUSTOM_KLOTSKI_DB"
+
 func GetDB() *gorm.DB {
 	once.Do(func() {
-		homeDir, _ := homedir.Dir()
-		dbDir := homeDir + "/Documents/CustomKlotski"
-		_ = os.MkdirAll(dbDir, 0755)
-		println(dbDir)
-		db, _ = gorm.Open(sqlite.Open(dbDir+"/data.db"), &gorm.Config{})
-		_ = db.AutoMigrate(&Game{}, &Tag{})
+		dbFile := os.Getenv(DBFileEnv)
+		if dbFile == "" {
+			homeDir, _ := homedir.Dir()
+			dbDir := homeDir + "/Documents/CustomKlotski"
+			_ = os.MkdirAll(dbDir, 0755)
+			println(dbDir)
+			dbFile = dbDir + "/data.db"
+		}
+		db, _ = gorm.Open(sqlite.Open(dbFile), &gorm.Config{})
+		_ = db.AutoMigrate(&Game{}, &Tag{}, &Setting{}, &Solution{})
 	})
 	return db
 }
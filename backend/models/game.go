@@ -1,9 +1,17 @@
 package models
 
+import "github.com/addlete/custom-klotski/backend/utils"
+
 type Game struct {
-	ID        uint   `gorm:"primaryKey" json:"id"`
-	Name      string `gorm:"type:varchar(30);not null" json:"name"`
-	GameShape string `gorm:"type:TEXT;not null" json:"gameShape"`
-	Md5       string `gorm:"type:varchar(32);not null;uniqueIndex" json:"md5"`
-	Tags      []*Tag `gorm:"many2many:game_tags;" json:"tags"`
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"type:varchar(30);not null" json:"name"`
+	GameShape   string `gorm:"type:TEXT;not null" json:"gameShape"`
+	Goal        string `gorm:"type:TEXT" json:"goal"`                            // 获胜条件的JSON，为空时王棋到达出口即获胜，棋子索引按布局数据中的顺序
+	SolveStats  string `gorm:"type:TEXT" json:"solveStats"`                      // 最近一次求解的统计的JSON，没有求解过时为空
+	Md5         string `gorm:"type:varchar(32);not null;uniqueIndex" json:"md5"` // 由后端按 utils.PuzzleMd5 计算
+	HashVersion int    `gorm:"not null;default:0" json:"hashVersion"`            // Md5 所用摘要算法的版本，0为旧版前端计算的md5
+	Tags        []*Tag `gorm:"many2many:game_tags;" json:"tags"`
+	// 设计器提交的布局，棋子按设计器中的顺序，不保存；
+	// 保存时不为空则由后端按 utils.GameData2GameShape 和 utils.GameData2Goal 生成 GameShape 和 Goal
+	GameData *utils.GameData `gorm:"-" json:"gameData,omitempty"`
 }
@@ -0,0 +1,17 @@
+package models
+
+// Setting 应用设置，只有一行
+type Setting struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	MaxStates int  `gorm:"not null" json:"maxStates"` // 求解时最多保存的局面数，为0时使用求解器的默认值
+	MaxTime   int  `gorm:"not null" json:"maxTime"`   // 求解的最长用时，秒，为0时不限制
+	MaxMemory int  `gorm:"not null" json:"maxMemory"` // 每次求解的数据结构占用内存的大致上限，MB，为0时不限制
+}
+
+// DefaultSetting 还没有保存过设置时使用的默认值
+var DefaultSetting = Setting{
+	ID:        1,
+	MaxStates: 20000000,
+	MaxTime:   600,
+	MaxMemory: 4096,
+}
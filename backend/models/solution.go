@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Solution 求解结果的缓存，按布局的规范摘要和计步方式区分
+type Solution struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PuzzleHash    string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_solution_key" json:"puzzleHash"`
+	Metric        string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_solution_key" json:"metric"`
+	SolverVersion int       `gorm:"not null" json:"solverVersion"` // 求解时的求解器版本，与当前版本不同时不再使用
+	Algorithm     string    `gorm:"type:varchar(16)" json:"algorithm"`
+	Length        int       `gorm:"not null" json:"length"`
+	Steps         string    `gorm:"type:TEXT;not null" json:"steps"` // 按格子记录的步骤的JSON，见 utils.CellStep
+	Stats         string    `gorm:"type:TEXT" json:"stats"`          // 求解统计的JSON
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -0,0 +1,142 @@
+package utils
+
+import "context"
+
+// AnalyzeResult 布局的状态空间统计
+type AnalyzeResult struct {
+	Metric           Metric `json:"metric"`
+	Reachable        int    `json:"reachable"`        // 从开局可以到达的局面数，含开局
+	Winning          int    `json:"winning"`          // 其中已经获胜的局面数
+	StartDistance    int    `json:"startDistance"`    // 开局距目标的最少步数，无解时为-1
+	MaxDistance      int    `json:"maxDistance"`      // 可以获胜的局面中，距目标最远的步数
+	Histogram        []int  `json:"histogram"`        // Histogram[d]为距目标d步的局面数
+	Unsolvable       int    `json:"unsolvable"`       // 无法到达目标的局面数
+	OptimalSolutions int64  `json:"optimalSolutions"` // 开局到目标的最优解法数，按局面序列区分，超过上限时取上限
+}
+
+// maxSolutionCount 解法数的上限，保证传到前端时不丢失精度
+const maxSolutionCount = 1 << 53
+
+// Analyze 穷举开局所在的整个连通分量，统计每个局面到目标的距离
+// 移动都是可逆的，所以从所有获胜局面出发反向逐层搜索即可得到每个局面的距离
+// 统计的是真实的局面数，不按对称规约
+func (gs *GameSolve) Analyze(ctx context.Context) (AnalyzeResult, error) {
+	gs.disableSymmetry()
+	result := AnalyzeResult{
+		Metric:        gs.metric,
+		StartDistance: -1,
+		Histogram:     []int{},
+	}
+	gs.startLimits()
+	progress := newProgressReporter(gs.OnProgress)
+
+	// 从开局出发穷举所有局面，同时记下获胜局面
+	var winList []int32
+	for head := int32(0); int(head) < gs.store.count; head++ {
+		if head%1024 == 0 {
+			if err := gs.checkLimits(ctx, gs.store.count); err != nil {
+				result.Reachable = gs.store.count
+				return result, err
+			}
+			progress.report(int(head), gs.store.count-int(head), 0)
+		}
+		gs.decodeState(gs.store.get(head), gs.state.PieceList)
+		gs.gameState2Board(gs.state)
+		if gs.isWin(gs.state) {
+			winList = append(winList, head)
+		}
+		gs.forEachMove(func(pieceIndex int16) bool {
+			gs.store.add(gs.encodeState(gs.state.PieceList), head)
+			return false
+		})
+	}
+	result.Reachable = gs.store.count
+	result.Winning = len(winList)
+
+	// 从所有获胜局面出发，逐层计算每个局面到目标的距离，
+	// 同时累计每个局面到目标的最优路线数：等于距离少一步的相邻局面的路线数之和
+	distance := make([]int32, gs.store.count)
+	ways := make([]int64, gs.store.count)
+	for i := range distance {
+		distance[i] = -1
+	}
+	for _, index := range winList {
+		distance[index] = 0
+		ways[index] = 1
+	}
+	queue := winList
+	gs.searchMemory = func() uint64 {
+		return 4*uint64(cap(queue)+cap(distance)) + 8*uint64(cap(ways))
+	}
+	defer func() {
+		gs.searchMemory = nil
+	}()
+	for i := 0; i < len(queue); i++ {
+		index := queue[i]
+		if i%1024 == 0 {
+			if err := gs.checkLimits(ctx, 0); err != nil {
+				return result, err
+			}
+			progress.report(result.Reachable+i, len(queue)-i, int(distance[index]))
+		}
+		gs.decodeState(gs.store.get(index), gs.state.PieceList)
+		gs.gameState2Board(gs.state)
+		gs.forEachMove(func(pieceIndex int16) bool {
+			next := gs.store.find(gs.encodeState(gs.state.PieceList))
+			if next >= 0 && distance[next] < 0 {
+				distance[next] = distance[index] + 1
+				queue = append(queue, next)
+			}
+			if next >= 0 && distance[next] == distance[index]+1 {
+				ways[next] += ways[index]
+				if ways[next] > maxSolutionCount {
+					ways[next] = maxSolutionCount
+				}
+			}
+			return false
+		})
+	}
+	gs.distance = distance
+
+	for _, d := range distance {
+		if d < 0 {
+			result.Unsolvable++
+			continue
+		}
+		for int(d) >= len(result.Histogram) {
+			result.Histogram = append(result.Histogram, 0)
+		}
+		result.Histogram[d]++
+	}
+	result.MaxDistance = len(result.Histogram) - 1
+	result.StartDistance = int(distance[0])
+	result.OptimalSolutions = ways[0]
+	return result, nil
+}
+
+// SolutionCount 最优解法是否唯一
+type SolutionCount struct {
+	Metric           Metric `json:"metric"`
+	Moves            int    `json:"moves"`            // 最优解法的步数，无解时为-1
+	OptimalSolutions int64  `json:"optimalSolutions"` // 最优解法数
+	Unique           bool   `json:"unique"`           // 最优解法是否唯一
+	Reachable        int    `json:"reachable"`        // 从开局可以到达的局面数
+	DeadEnds         int    `json:"deadEnds"`         // 其中无法到达目标的局面数
+}
+
+// CountSolutions 统计最优解法数和死局数
+// 按棋子计步时同一棋子的连续移动算一步，统计的就是不计移动路线的解法数
+func (gs *GameSolve) CountSolutions(ctx context.Context) (SolutionCount, error) {
+	analysis, err := gs.Analyze(ctx)
+	if err != nil {
+		return SolutionCount{}, err
+	}
+	return SolutionCount{
+		Metric:           analysis.Metric,
+		Moves:            analysis.StartDistance,
+		OptimalSolutions: analysis.OptimalSolutions,
+		Unique:           analysis.OptimalSolutions == 1,
+		Reachable:        analysis.Reachable,
+		DeadEnds:         analysis.Unsolvable,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+func analyzeGame(t *testing.T, game GameData, options SolveOptions) AnalyzeResult {
+	t.Helper()
+	gs := GameSolve{Options: options}
+	gs.Init(game)
+	analysis, err := gs.Analyze(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return analysis
+}
+
+// TestAnalyze 经典布局的状态空间统计，对称规约不影响统计的局面数
+func TestAnalyze(t *testing.T) {
+	cases := []struct {
+		metric    Metric
+		distance  int
+		solutions int64
+	}{
+		{MetricStep, 116, 4112640000},
+		{MetricStraight, 90, 4096},
+		{MetricPiece, 81, 256},
+	}
+	for _, c := range cases {
+		for _, noSymmetry := range []bool{false, true} {
+			analysis := analyzeGame(t, classicGame(), SolveOptions{Metric: c.metric, NoSymmetry: noSymmetry})
+			if analysis.Reachable != 25955 || analysis.Winning != 964 || analysis.Unsolvable != 0 {
+				t.Errorf("%s: reachable %d, winning %d, unsolvable %d", c.metric, analysis.Reachable, analysis.Winning, analysis.Unsolvable)
+			}
+			if analysis.StartDistance != c.distance || analysis.OptimalSolutions != c.solutions {
+				t.Errorf("%s: start distance %d, %d solutions", c.metric, analysis.StartDistance, analysis.OptimalSolutions)
+			}
+			total := 0
+			for _, count := range analysis.Histogram {
+				total += count
+			}
+			if total != analysis.Reachable || analysis.Histogram[0] != analysis.Winning ||
+				analysis.MaxDistance != len(analysis.Histogram)-1 || analysis.MaxDistance < analysis.StartDistance {
+				t.Errorf("%s: histogram %v, max distance %d", c.metric, analysis.Histogram, analysis.MaxDistance)
+			}
+		}
+	}
+}
+
+// TestAnalyzeUnsolvable 无法获胜的布局，所有局面都无法到达目标
+func TestAnalyzeUnsolvable(t *testing.T) {
+	// 右边的棋子挡住了去路，左边的棋子到不了最右边
+	game := lineGame(3)
+	game.PieceList = append(game.PieceList, Piece{Shape{{true}}, Pos{0, 1}})
+	game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 2}}}}
+	analysis := analyzeGame(t, game, SolveOptions{})
+	if analysis.Reachable != 3 || analysis.Unsolvable != 3 || analysis.Winning != 0 ||
+		analysis.StartDistance != -1 || analysis.MaxDistance != -1 || analysis.OptimalSolutions != 0 {
+		t.Errorf("got %+v", analysis)
+	}
+}
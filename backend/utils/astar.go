@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sort"
+)
+
+// openItem A*待展开的局面
+type openItem struct {
+	f     int32
+	g     int32
+	index int32
+}
+
+// openList 按f从小到大排列的小顶堆，f相同时优先展开g大的，更快接近目标
+type openList []openItem
+
+func (l openList) Len() int { return len(l) }
+func (l openList) Less(i, j int) bool {
+	if l[i].f != l[j].f {
+		return l[i].f < l[j].f
+	}
+	return l[i].g > l[j].g
+}
+func (l openList) Swap(i, j int)       { l[i], l[j] = l[j], l[i] }
+func (l *openList) Push(x interface{}) { *l = append(*l, x.(openItem)) }
+func (l *openList) Pop() interface{} {
+	old := *l
+	item := old[len(old)-1]
+	*l = old[:len(old)-1]
+	return item
+}
+
+// heuristic 选项中指定的启发函数，未指定或不存在时使用默认的
+func (gs *GameSolve) heuristic() Heuristic {
+	if heuristic, ok := heuristicMap[gs.Options.Heuristic]; ok {
+		return heuristic
+	}
+	return heuristicMap[defaultHeuristic]
+}
+
+func (gs *GameSolve) maxStates() int {
+	if gs.Options.MaxStates > 0 {
+		return gs.Options.MaxStates
+	}
+	return defaultMaxStates
+}
+
+// solveAStar A*搜索，局面数超过上限时返回 ErrLimitExceeded
+// 启发函数不高估时，出队的第一个获胜局面即为最优解
+func (gs *GameSolve) solveAStar(ctx context.Context) (SolveResult, error) {
+	heuristic := gs.heuristic()
+	result := SolveResult{
+		Steps:     []Step{},
+		Metric:    gs.metric,
+		Algorithm: AlgorithmAStar,
+	}
+	gs.gameState2Board(gs.state)
+	gScore := []int32{0} // 开局到每个局面的已知最短步数
+	open := &openList{{f: int32(heuristic.Estimate(gs)), g: 0, index: 0}}
+	gs.searchMemory = func() uint64 {
+		return 4*uint64(cap(gScore)) + 12*uint64(cap(*open))
+	}
+	defer func() {
+		gs.searchMemory = nil
+	}()
+
+	progress := newProgressReporter(gs.OnProgress)
+	explored := 0
+	for open.Len() > 0 {
+		item := heap.Pop(open).(openItem)
+		if item.g != gScore[item.index] {
+			continue // 已经找到更短的路线，跳过过期的记录
+		}
+		if explored%1024 == 0 {
+			if err := gs.checkLimits(ctx, gs.store.count); err != nil {
+				result.ForwardExplored = gs.store.count
+				return result, err
+			}
+			progress.report(explored, open.Len(), int(item.g))
+		}
+		gs.stats.observe(open.Len(), int(item.g))
+		explored++
+
+		gs.decodeState(gs.store.get(item.index), gs.state.PieceList)
+		gs.gameState2Board(gs.state)
+		if gs.isWin(gs.state) {
+			result.Steps = gs.humanSteps(gs.store.path(item.index))
+			result.Length = int(item.g)
+			result.Optimal = heuristic.Admissible
+			result.ForwardExplored = gs.store.count
+			return result, nil
+		}
+		g := item.g + 1
+		gs.forEachMove(func(pieceIndex int16) bool {
+			index, isNew := gs.store.add(gs.encodeState(gs.state.PieceList), item.index)
+			gs.stats.addGenerated(isNew)
+			if isNew {
+				gScore = append(gScore, g)
+			} else if g < gScore[index] {
+				gScore[index] = g
+				gs.store.parent[index] = item.index
+			} else {
+				return false
+			}
+			heap.Push(open, openItem{f: g + int32(heuristic.Estimate(gs)), g: g, index: index})
+			return false
+		})
+	}
+	result.ForwardExplored = gs.store.count
+	return result, errors.New("no solution")
+}
+
+// idaSearch 一次IDA*搜索的状态
+type idaSearch struct {
+	gs        *GameSolve
+	ctx       context.Context
+	heuristic Heuristic
+	table     *stateStore // 置换表，记录本轮到达过的局面，容量有限，满了之后不再记录
+	tableG    []int32     // 置换表中每个局面本轮到达时的最小步数
+	capacity  int
+	path      [][]byte        // 开局到当前局面经过的局面，不含开局
+	pathSet   map[string]bool // 当前路线上的局面，避免绕圈
+	bound     int
+	explored  int
+	progress  *progressReporter
+}
+
+type idaChild struct {
+	state    []byte
+	estimate int
+}
+
+// solveIDAStar 迭代加深A*搜索，内存只用于当前路线和容量有限的置换表
+func (gs *GameSolve) solveIDAStar(ctx context.Context) (SolveResult, error) {
+	heuristic := gs.heuristic()
+	result := SolveResult{
+		Steps:     []Step{},
+		Metric:    gs.metric,
+		Algorithm: AlgorithmIDAStar,
+	}
+	search := &idaSearch{
+		gs:        gs,
+		ctx:       ctx,
+		heuristic: heuristic,
+		capacity:  gs.maxStates(),
+		pathSet:   map[string]bool{},
+		progress:  newProgressReporter(gs.OnProgress),
+	}
+	// 置换表每轮重建，按当前这一轮的表计算内存
+	gs.searchMemory = func() uint64 {
+		if search.table == nil {
+			return 0
+		}
+		return search.table.memory() + 4*uint64(cap(search.tableG))
+	}
+	defer func() {
+		gs.searchMemory = nil
+	}()
+	start := append([]byte{}, gs.store.get(0)...)
+	gs.decodeState(start, gs.state.PieceList)
+	gs.gameState2Board(gs.state)
+	search.bound = heuristic.Estimate(gs)
+	for {
+		search.table = newStateStore(len(start))
+		search.tableG = search.tableG[:0]
+		search.pathSet[string(start)] = true
+		next, found, err := search.dfs(start, 0)
+		result.ForwardExplored = search.explored
+		if err != nil {
+			return result, err
+		}
+		if found {
+			result.Steps = gs.humanSteps(search.path)
+			result.Length = len(search.path)
+			result.Optimal = heuristic.Admissible
+			return result, nil
+		}
+		if next < 0 {
+			return result, errors.New("no solution")
+		}
+		search.bound = next
+	}
+}
+
+// dfs 在当前阈值内深度优先搜索，返回超出阈值的最小f值（没有时为-1）以及是否找到目标
+func (s *idaSearch) dfs(state []byte, g int) (int, bool, error) {
+	gs := s.gs
+	if s.explored%1024 == 0 {
+		if err := gs.checkLimits(s.ctx, 0); err != nil {
+			return -1, false, err
+		}
+		s.progress.report(s.explored, len(s.path), s.bound)
+	}
+	s.explored++
+	gs.stats.observe(len(s.path), g)
+
+	gs.decodeState(state, gs.state.PieceList)
+	gs.gameState2Board(gs.state)
+	if gs.isWin(gs.state) {
+		return g, true, nil
+	}
+	// 本轮已经以更少的步数到达过这个局面，它能搜到的范围已经搜过了
+	if index := s.table.find(state); index >= 0 {
+		if s.tableG[index] <= int32(g) {
+			gs.stats.Duplicates++
+			return -1, false, nil
+		}
+		s.tableG[index] = int32(g)
+	} else if s.table.count < s.capacity {
+		s.table.add(state, -1)
+		s.tableG = append(s.tableG, int32(g))
+	}
+
+	var children []idaChild
+	gs.forEachMove(func(pieceIndex int16) bool {
+		children = append(children, idaChild{
+			state:    append([]byte{}, gs.encodeState(gs.state.PieceList)...),
+			estimate: s.heuristic.Estimate(gs),
+		})
+		return false
+	})
+	gs.stats.Generated += len(children)
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].estimate < children[j].estimate
+	})
+
+	minNext := -1
+	for _, child := range children {
+		f := g + 1 + child.estimate
+		if f > s.bound {
+			if minNext < 0 || f < minNext {
+				minNext = f
+			}
+			continue
+		}
+		if s.pathSet[string(child.state)] {
+			gs.stats.Duplicates++
+			continue
+		}
+		s.path = append(s.path, child.state)
+		s.pathSet[string(child.state)] = true
+		next, found, err := s.dfs(child.state, g+1)
+		if found || err != nil {
+			return next, found, err
+		}
+		s.path = s.path[:len(s.path)-1]
+		delete(s.pathSet, string(child.state))
+		if next >= 0 && (minNext < 0 || next < minNext) {
+			minNext = next
+		}
+	}
+	return minNext, false, nil
+}
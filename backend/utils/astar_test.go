@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+// TestAStar 不高估的启发函数求出的解法长度与广度优先搜索相同，放大的启发函数不保证最优
+func TestAStar(t *testing.T) {
+	lengths := map[Metric]int{MetricStep: 116, MetricStraight: 90, MetricPiece: 81}
+	for metric, length := range lengths {
+		for _, heuristic := range []string{"", "goal", "zero", "weighted"} {
+			result, err := solveGame(t, classicGame(), SolveOptions{Metric: metric, Algorithm: AlgorithmAStar, Heuristic: heuristic})
+			if err != nil {
+				t.Errorf("%s, %q: %v", metric, heuristic, err)
+				continue
+			}
+			verify, err := Verify(classicGame(), result.Steps)
+			if err != nil || !verify.Solved {
+				t.Errorf("%s, %q: solution does not verify", metric, heuristic)
+			}
+			if heuristic == "weighted" {
+				if result.Optimal || result.Length < length {
+					t.Errorf("%s, weighted: length %d, optimal %v", metric, result.Length, result.Optimal)
+				}
+				continue
+			}
+			if result.Length != length || !result.Optimal {
+				t.Errorf("%s, %q: length %d, optimal %v, want %d", metric, heuristic, result.Length, result.Optimal, length)
+			}
+		}
+	}
+}
+
+// TestIDAStar 迭代加深A*的解法长度与广度优先搜索相同
+// 经典布局用IDA*求解太慢，改用离开局较近的目标布局
+func TestIDAStar(t *testing.T) {
+	solved, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game := classicGame()
+	game.Goal = &Goal{Layout: layoutAfter(t, game, solved.Steps, 12)}
+	for _, metric := range []Metric{MetricStep, MetricStraight, MetricPiece} {
+		bfs, err := solveGame(t, game, SolveOptions{Metric: metric})
+		if err != nil {
+			t.Fatalf("%s: %v", metric, err)
+		}
+		for _, heuristic := range []string{"goal", "zero"} {
+			result, err := solveGame(t, game, SolveOptions{Metric: metric, Algorithm: AlgorithmIDAStar, Heuristic: heuristic})
+			if err != nil {
+				t.Errorf("%s, %s: %v", metric, heuristic, err)
+				continue
+			}
+			if result.Length != bfs.Length || !result.Optimal || result.Algorithm != AlgorithmIDAStar {
+				t.Errorf("%s, %s: length %d, BFS length %d", metric, heuristic, result.Length, bfs.Length)
+			}
+			verify, err := Verify(game, result.Steps)
+			if err != nil || !verify.Solved {
+				t.Errorf("%s, %s: solution does not verify", metric, heuristic)
+			}
+		}
+	}
+}
+
+// TestGoalHeuristicAdmissible 默认启发函数在每个可达局面上都不超过到目标的真实距离
+func TestGoalHeuristicAdmissible(t *testing.T) {
+	solved, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	layoutGame := classicGame()
+	layoutGame.Goal = &Goal{Layout: layoutAfter(t, layoutGame, solved.Steps, 30)}
+	pieceGame := classicGame()
+	pieceGame.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{3, 1}}, {PieceIndex: 5, Position: Pos{0, 1}}}}
+	games := map[string]GameData{"king": classicGame(), "layout": layoutGame, "pieces": pieceGame}
+	for name, game := range games {
+		for _, metric := range []Metric{MetricStep, MetricStraight, MetricPiece} {
+			gs := GameSolve{Options: SolveOptions{Metric: metric}}
+			gs.Init(game)
+			if _, err := gs.Analyze(context.Background()); err != nil {
+				t.Fatalf("%s, %s: %v", name, metric, err)
+			}
+			for index, distance := range gs.distance {
+				if distance < 0 {
+					continue
+				}
+				gs.decodeState(gs.store.get(int32(index)), gs.state.PieceList)
+				gs.gameState2Board(gs.state)
+				if estimate := goalHeuristic(&gs); estimate > int(distance) {
+					t.Errorf("%s, %s: state %d estimated %d, distance %d", name, metric, index, estimate, distance)
+					break
+				}
+			}
+		}
+	}
+}
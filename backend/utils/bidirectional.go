@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"context"
+	"errors"
+)
+
+// bfsSide 双向搜索中的一侧
+type bfsSide struct {
+	store *stateStore
+	head  int32 // 下一个待展开的局面
+	depth int   // 当前待展开这一层的深度
+}
+
+func (side *bfsSide) frontier() int {
+	return side.store.count - int(side.head)
+}
+
+// solveBidirectional 双向广度优先搜索
+// 从开局和目标布局同时逐层搜索，每次展开待展开局面较少的一侧，
+// 某一层展开完毕时若两侧已经相遇，取这一层中最短的相遇路线，即为最优解
+func (gs *GameSolve) solveBidirectional(ctx context.Context) (SolveResult, error) {
+	result := SolveResult{
+		Steps:     []Step{},
+		Metric:    gs.metric,
+		Algorithm: AlgorithmBidirectional,
+		Optimal:   true,
+	}
+	forward := &bfsSide{store: gs.store}
+	backward := &bfsSide{store: newStateStore(gs.store.size)}
+	backward.store.add(gs.targetState, -1)
+	gs.searchMemory = backward.store.memory
+	defer func() {
+		gs.searchMemory = nil
+	}()
+	if string(forward.store.get(0)) == string(gs.targetState) {
+		result.ForwardExplored = forward.store.count
+		result.BackwardExplored = backward.store.count
+		return result, nil
+	}
+
+	progress := newProgressReporter(gs.OnProgress)
+	explored := 0
+	for forward.frontier() > 0 && backward.frontier() > 0 {
+		side, other := forward, backward
+		if backward.frontier() < forward.frontier() {
+			side, other = backward, forward
+		}
+		best, sideMeet, otherMeet := -1, int32(-1), int32(-1)
+		levelEnd := int32(side.store.count)
+		for ; side.head < levelEnd; side.head++ {
+			// 每展开一批局面检查一次是否取消或超出限制，并按间隔回调进度
+			if explored%1024 == 0 {
+				if err := gs.checkLimits(ctx, forward.store.count+backward.store.count); err != nil {
+					result.ForwardExplored = forward.store.count
+					result.BackwardExplored = backward.store.count
+					return result, err
+				}
+				progress.report(explored, forward.frontier()+backward.frontier(), forward.depth+backward.depth)
+			}
+			explored++
+			gs.stats.observe(forward.frontier()+backward.frontier(), forward.depth+backward.depth)
+
+			head := side.head
+			gs.decodeState(side.store.get(head), gs.state.PieceList)
+			gs.gameState2Board(gs.state)
+			gs.forEachMove(func(pieceIndex int16) bool {
+				index, isNew := side.store.add(gs.encodeState(gs.state.PieceList), head)
+				gs.stats.addGenerated(isNew)
+				if !isNew {
+					return false
+				}
+				otherIndex := other.store.find(side.store.get(index))
+				if otherIndex < 0 {
+					return false
+				}
+				length := side.depth + 1 + other.store.depth(otherIndex)
+				if best < 0 || length < best {
+					best, sideMeet, otherMeet = length, index, otherIndex
+				}
+				return false
+			})
+		}
+		side.depth++
+		if best >= 0 {
+			forwardMeet, backwardMeet := sideMeet, otherMeet
+			if side == backward {
+				forwardMeet, backwardMeet = otherMeet, sideMeet
+			}
+			// 开局到相遇局面，再沿反向搜索的父局面走到目标布局
+			states := forward.store.path(forwardMeet)
+			for index := backward.store.parent[backwardMeet]; index >= 0; index = backward.store.parent[index] {
+				states = append(states, backward.store.get(index))
+			}
+			result.Steps = gs.humanSteps(states)
+			result.Length = best
+			result.ForwardExplored = forward.store.count
+			result.BackwardExplored = backward.store.count
+			return result, nil
+		}
+	}
+	result.ForwardExplored = forward.store.count
+	result.BackwardExplored = backward.store.count
+	return result, errors.New("no solution")
+}
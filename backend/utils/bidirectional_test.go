@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+// layoutAfter 从开局走完解法的前n步之后每个棋子的位置
+func layoutAfter(t *testing.T, game GameData, steps []Step, n int) []Pos {
+	t.Helper()
+	gs := replaySolve(game)
+	for _, step := range steps[:n] {
+		if !gs.TryStep(step) {
+			t.Fatalf("cannot replay step %+v", step)
+		}
+	}
+	return gs.Positions()
+}
+
+// TestBidirectional 目标布局完整时，双向搜索的解法长度与单向广度优先搜索相同
+func TestBidirectional(t *testing.T) {
+	solved, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game := classicGame()
+	game.Goal = &Goal{Layout: layoutAfter(t, game, solved.Steps, 40)}
+
+	for _, metric := range []Metric{MetricStep, MetricStraight, MetricPiece} {
+		bfs, err := solveGame(t, game, SolveOptions{Metric: metric, Algorithm: AlgorithmBFS})
+		if err != nil {
+			t.Fatalf("%s: %v", metric, err)
+		}
+		result, err := solveGame(t, game, SolveOptions{Metric: metric, Algorithm: AlgorithmBidirectional})
+		if err != nil {
+			t.Fatalf("%s: %v", metric, err)
+		}
+		if result.Algorithm != AlgorithmBidirectional || result.BackwardExplored == 0 {
+			t.Errorf("%s: algorithm %s, backward explored %d", metric, result.Algorithm, result.BackwardExplored)
+		}
+		if result.Length != bfs.Length || !result.Optimal {
+			t.Errorf("%s: length %d, BFS length %d", metric, result.Length, bfs.Length)
+		}
+		if metric == MetricPiece && result.Length > 40 {
+			t.Errorf("length %d is longer than the replayed route", result.Length)
+		}
+		verify, err := Verify(game, result.Steps)
+		if err != nil || !verify.Solved {
+			t.Errorf("%s: solution does not verify", metric)
+		}
+	}
+}
+
+// TestBidirectionalFallback 开局即为目标时不需要移动；没有目标布局时退回单向搜索
+func TestBidirectionalFallback(t *testing.T) {
+	game := classicGame()
+	game.Goal = &Goal{Layout: layoutAfter(t, game, nil, 0)}
+	result, err := solveGame(t, game, SolveOptions{Algorithm: AlgorithmBidirectional})
+	if err != nil || result.Length != 0 {
+		t.Errorf("start is the target: length %d, %v", result.Length, err)
+	}
+
+	gs := GameSolve{Options: SolveOptions{Metric: MetricPiece, Algorithm: AlgorithmBidirectional}}
+	gs.Init(classicGame())
+	result, err = gs.Solve(context.Background())
+	if err != nil || result.Algorithm != AlgorithmBFS || result.Length != 81 {
+		t.Errorf("no target layout: algorithm %s, length %d, %v", result.Algorithm, result.Length, err)
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import "errors"
+
+// CellStep 用棋子占据的格子代替棋子索引的步骤
+// 缓存的解法按规范摘要查找，同形状的棋子在不同布局数据中的顺序可能不同，按格子找棋子才能正确重放
+type CellStep struct {
+	Cell      Pos       `json:"cell"` // 移动前棋子占据的第一个格子，按行优先
+	Direction []int16   `json:"direction"`
+	Path      [][]int16 `json:"path"`
+}
+
+// ErrInvalidSteps 步骤无法在布局上重放
+var ErrInvalidSteps = errors.New("invalid steps")
+
+// StepsToCells 在布局上重放解法，把每一步的棋子索引换成棋子占据的格子
+func StepsToCells(game GameData, steps []Step) ([]CellStep, error) {
+	gs := replaySolve(game)
+	cellSteps := make([]CellStep, len(steps))
+	for i, step := range steps {
+		if step.PieceIndex < 0 || int(step.PieceIndex) >= len(gs.state.PieceList) {
+			return nil, ErrInvalidSteps
+		}
+		pos := gs.pieceToPos(gs.state.PieceList[step.PieceIndex])
+		cell := gs.pieceKindShapeList[step.PieceIndex].Cells[0]
+		cellSteps[i] = CellStep{
+			Cell:      Pos{pos[0] + cell[0], pos[1] + cell[1]},
+			Direction: step.Direction,
+			Path:      step.Path,
+		}
+		if !gs.TryStep(step) {
+			return nil, ErrInvalidSteps
+		}
+	}
+	return cellSteps, nil
+}
+
+// CellsToSteps 在布局上重放解法，按格子找到每一步移动的棋子
+func CellsToSteps(game GameData, cellSteps []CellStep) ([]Step, error) {
+	gs := replaySolve(game)
+	steps := make([]Step, len(cellSteps))
+	for i, cellStep := range cellSteps {
+		cell := cellStep.Cell
+		if len(cell) != 2 || cell[0] < 0 || cell[0] >= gs.boardRows || cell[1] < 0 || cell[1] >= gs.boardCols {
+			return nil, ErrInvalidSteps
+		}
+		grid := gs.state.Board[cell[0]*gs.boardCols+cell[1]]
+		if grid <= 0 {
+			return nil, ErrInvalidSteps
+		}
+		steps[i] = Step{
+			PieceIndex: grid - 1,
+			Direction:  cellStep.Direction,
+			Path:       cellStep.Path,
+		}
+		if !gs.TryStep(steps[i]) {
+			return nil, ErrInvalidSteps
+		}
+	}
+	return steps, nil
+}
+
+// replaySolve 用于重放解法的求解器，每一步可以是同一棋子的任意连续移动
+func replaySolve(game GameData) *GameSolve {
+	gs := &GameSolve{
+		Options: SolveOptions{
+			Metric:     MetricPiece,
+			NoSymmetry: true,
+		},
+	}
+	gs.Init(game)
+	return gs
+}
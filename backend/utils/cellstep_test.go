@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCellSteps 按格子记录的解法可以在棋子顺序不同的同一布局上重放
+func TestCellSteps(t *testing.T) {
+	solved, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cellSteps, err := StepsToCells(classicGame(), solved.Steps)
+	if err != nil || len(cellSteps) != len(solved.Steps) {
+		t.Fatalf("%d cell steps, %v", len(cellSteps), err)
+	}
+
+	// 王棋放到最后，竖放的棋子倒序，PuzzleHash 不变
+	reordered := classicGame()
+	pieces := reordered.PieceList
+	reordered.PieceList = []Piece{pieces[4], pieces[3], pieces[2], pieces[1], pieces[5], pieces[6], pieces[7], pieces[8], pieces[9], pieces[0]}
+	reordered.KingPieceIndex = 9
+	if PuzzleHash(reordered) != PuzzleHash(classicGame()) {
+		t.Fatal("reordered layout hashes differently")
+	}
+	steps, err := CellsToSteps(reordered, cellSteps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify, err := Verify(reordered, steps)
+	if err != nil || !verify.Solved || verify.Moves != solved.Length {
+		t.Errorf("replayed on the reordered layout: %+v, %v", verify, err)
+	}
+	if steps[len(steps)-1].PieceIndex != 9 {
+		t.Errorf("last move by piece %d, want the king", steps[len(steps)-1].PieceIndex)
+	}
+}
+
+// TestCellStepsInvalid 空格、越界或走不通的步骤返回 ErrInvalidSteps
+func TestCellStepsInvalid(t *testing.T) {
+	cases := [][]CellStep{
+		{{Cell: Pos{4, 1}, Direction: []int16{0, 1}}}, // 空格
+		{{Cell: Pos{5, 0}, Direction: []int16{0, 1}}}, // 越界
+		{{Cell: Pos{0}, Direction: []int16{0, 1}}},    // 格子不完整
+		{{Cell: Pos{0, 1}, Direction: []int16{1, 0}}}, // 王棋被挡住
+	}
+	for i, cellSteps := range cases {
+		if _, err := CellsToSteps(classicGame(), cellSteps); !errors.Is(err, ErrInvalidSteps) {
+			t.Errorf("case %d: got %v", i, err)
+		}
+	}
+	if _, err := StepsToCells(classicGame(), []Step{{PieceIndex: 10, Direction: []int16{0, 1}}}); !errors.Is(err, ErrInvalidSteps) {
+		t.Errorf("piece index: got %v", err)
+	}
+}
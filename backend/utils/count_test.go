@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+func countSolutions(t *testing.T, game GameData, metric Metric) SolutionCount {
+	t.Helper()
+	gs := GameSolve{Options: SolveOptions{Metric: metric}}
+	gs.Init(game)
+	count, err := gs.CountSolutions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+// TestCountSolutions 小棋盘上可以数清的最优解法数
+func TestCountSolutions(t *testing.T) {
+	// 2行2列，棋子从左上角走到右下角：逐格走有先右后下和先下后右两种，按棋子计步只有一步
+	turn := GameData{
+		BoardRows:      2,
+		BoardCols:      2,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 0}}},
+		Goal:           &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{1, 1}}}},
+	}
+	// 1行3列，两枚棋子都要右移一格，先后顺序只有一种
+	line := lineGame(3)
+	line.PieceList = append(line.PieceList, Piece{Shape{{true}}, Pos{0, 1}})
+	line.Goal = &Goal{Layout: []Pos{{0, 1}, {0, 2}}}
+	cases := []struct {
+		name      string
+		game      GameData
+		metric    Metric
+		moves     int
+		solutions int64
+	}{
+		{"turn", turn, MetricStep, 2, 2},
+		{"turn", turn, MetricStraight, 2, 2},
+		{"turn", turn, MetricPiece, 1, 1},
+		{"line", line, MetricStep, 2, 1},
+		{"classic", classicGame(), MetricPiece, 81, 256},
+	}
+	for _, c := range cases {
+		count := countSolutions(t, c.game, c.metric)
+		if count.Moves != c.moves || count.OptimalSolutions != c.solutions || count.Unique != (c.solutions == 1) || count.DeadEnds != 0 {
+			t.Errorf("%s, %s: %+v, want %d moves, %d solutions", c.name, c.metric, count, c.moves, c.solutions)
+		}
+	}
+}
+
+// TestCountSolutionsUnsolvable 无解时所有可达局面都是死局
+func TestCountSolutionsUnsolvable(t *testing.T) {
+	game := lineGame(3)
+	game.PieceList = append(game.PieceList, Piece{Shape{{true}}, Pos{0, 1}})
+	game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 2}}}}
+	count := countSolutions(t, game, MetricPiece)
+	if count.Moves != -1 || count.OptimalSolutions != 0 || count.Unique || count.Reachable != 3 || count.DeadEnds != 3 {
+		t.Errorf("got %+v", count)
+	}
+}
@@ -0,0 +1,558 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultMemoryBudget = 256 // 外存搜索默认的内存上限，MB
+	externalVersion     = 1   // 外存文件格式的版本，格式变化后旧的检查点作废
+	externalCheckpoint  = "checkpoint.json"
+)
+
+// ErrScratchDirBusy 同一布局的外存搜索正在使用这个临时目录
+var ErrScratchDirBusy = errors.New("scratch directory in use")
+
+// externalDirs 正在进行的外存搜索使用的临时目录
+// 目录只由布局决定，同时求解同一布局会互相删除片段文件，所以同一目录只允许一个搜索。
+// 这只是进程内的锁，不能防止另一个进程（比如同时打开的第二个程序）使用同一目录；
+// 不使用锁文件是因为程序异常退出后留下的锁文件会使检查点无法继续
+var externalDirs = struct {
+	sync.Mutex
+	busy map[string]bool
+}{busy: map[string]bool{}}
+
+// lockExternalDir 占用临时目录，目录已被占用时返回 ErrScratchDirBusy
+func lockExternalDir(dir string) (func(), error) {
+	externalDirs.Lock()
+	defer externalDirs.Unlock()
+	if externalDirs.busy[dir] {
+		return nil, ErrScratchDirBusy
+	}
+	externalDirs.busy[dir] = true
+	return func() {
+		externalDirs.Lock()
+		delete(externalDirs.busy, dir)
+		externalDirs.Unlock()
+	}, nil
+}
+
+// externalState 外存搜索的检查点，记录已经写完的最后一层
+type externalState struct {
+	Version  int    `json:"version"`
+	Key      string `json:"key"`
+	Depth    int    `json:"depth"`    // 已写完的最深一层
+	Explored int    `json:"explored"` // 已写完的各层局面数之和
+}
+
+// externalSearch 一次外存搜索，每层局面排序去重后存为一个文件
+type externalSearch struct {
+	gs        *GameSolve
+	ctx       context.Context
+	dir       string
+	size      int // 每个局面的字节数
+	budget    int // 内存中最多缓存的后继局面字节数
+	buf       []byte
+	runs      []string // 本层已写出的有序片段文件
+	progress  *progressReporter
+	explored  int
+	queueSize int
+}
+
+// solveExternal 外存广度优先搜索，适合内存放不下的超大状态空间
+// 移动都是可逆的，新一层只需与上一层和上上层去重（延迟重复检测）；
+// 后继局面在内存中攒满预算后排序写成片段，一层展开完毕后多路归并为下一层的有序文件。
+// 每写完一层更新检查点，中断后用同样的布局和临时目录再次求解会从检查点继续
+func (gs *GameSolve) solveExternal(ctx context.Context) (SolveResult, error) {
+	result := SolveResult{
+		Steps:     []Step{},
+		Metric:    gs.metric,
+		Algorithm: AlgorithmExternal,
+		Optimal:   true,
+	}
+	gs.gameState2Board(gs.state)
+	if gs.isWin(gs.state) {
+		result.ForwardExplored = 1
+		return result, nil
+	}
+
+	key := gs.puzzleKey()
+	dir := gs.Options.ScratchDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	dir = filepath.Join(dir, "custom-klotski-"+key[:16])
+	unlock, err := lockExternalDir(dir)
+	if err != nil {
+		return result, err
+	}
+	defer unlock()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return result, err
+	}
+	budget := gs.Options.MemoryBudget
+	if budget <= 0 {
+		budget = defaultMemoryBudget
+	}
+	search := &externalSearch{
+		gs:       gs,
+		ctx:      ctx,
+		dir:      dir,
+		size:     gs.store.size,
+		budget:   budget << 20,
+		progress: newProgressReporter(gs.OnProgress),
+	}
+	// 局面都在文件中，内存中只有攒着待写出的后继
+	gs.searchMemory = func() uint64 {
+		return uint64(cap(search.buf))
+	}
+	defer func() {
+		gs.searchMemory = nil
+	}()
+
+	// 从检查点继续，或者从开局开始
+	checkpoint, err := search.loadCheckpoint(key)
+	if err != nil {
+		if err := search.writeLayer(0, [][]byte{gs.store.get(0)}); err != nil {
+			return result, err
+		}
+		checkpoint = externalState{Version: externalVersion, Key: key, Explored: 1}
+		if err := search.saveCheckpoint(checkpoint); err != nil {
+			return result, err
+		}
+	}
+	search.explored = checkpoint.Explored
+	// 清理上次中断时留下的片段，返回时无论成功与否都清理本次的片段
+	search.removeRuns()
+	defer search.removeRuns()
+
+	for depth := checkpoint.Depth; ; depth++ {
+		generated := gs.stats.Generated
+		win, err := search.expandLayer(depth)
+		if err != nil {
+			result.ForwardExplored = search.explored
+			return result, err
+		}
+		if win != nil {
+			states, err := search.path(depth, win)
+			if err != nil {
+				return result, err
+			}
+			result.Steps = gs.humanSteps(states)
+			result.Length = depth + 1
+			result.ForwardExplored = search.explored
+			_ = os.RemoveAll(dir)
+			return result, nil
+		}
+		count, err := search.mergeLayer(depth + 1)
+		search.removeRuns()
+		if err != nil {
+			result.ForwardExplored = search.explored
+			return result, err
+		}
+		if count == 0 {
+			result.ForwardExplored = search.explored
+			_ = os.RemoveAll(dir)
+			return result, errors.New("no solution")
+		}
+		search.explored += count
+		// 后继在合并时才去重，没有进入下一层的都是重复的局面
+		gs.stats.Duplicates += gs.stats.Generated - generated - count
+		gs.stats.observe(count, depth+1)
+		checkpoint.Depth = depth + 1
+		checkpoint.Explored = search.explored
+		if err := search.saveCheckpoint(checkpoint); err != nil {
+			return result, err
+		}
+	}
+}
+
+func (s *externalSearch) layerFile(depth int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("layer-%06d.bin", depth))
+}
+
+func (s *externalSearch) loadCheckpoint(key string) (externalState, error) {
+	checkpoint := externalState{}
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, externalCheckpoint))
+	if err != nil {
+		return checkpoint, err
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, err
+	}
+	if checkpoint.Version != externalVersion || checkpoint.Key != key {
+		return checkpoint, errors.New("checkpoint mismatch")
+	}
+	for depth := 0; depth <= checkpoint.Depth; depth++ {
+		if _, err := os.Stat(s.layerFile(depth)); err != nil {
+			return checkpoint, err
+		}
+	}
+	return checkpoint, nil
+}
+
+// saveCheckpoint 先写临时文件再改名，保证检查点总是完整的
+func (s *externalSearch) saveCheckpoint(checkpoint externalState) error {
+	data, _ := json.Marshal(checkpoint)
+	file := filepath.Join(s.dir, externalCheckpoint)
+	if err := ioutil.WriteFile(file+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// writeLayer 把有序的局面写成一层
+func (s *externalSearch) writeLayer(depth int, states [][]byte) error {
+	file := s.layerFile(depth)
+	if err := ioutil.WriteFile(file+".tmp", bytes.Join(states, nil), 0644); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// removeRuns 删除本层的片段和写了一半的文件
+func (s *externalSearch) removeRuns() {
+	files, _ := filepath.Glob(filepath.Join(s.dir, "*.tmp"))
+	for _, file := range files {
+		_ = os.Remove(file)
+	}
+	s.runs = nil
+}
+
+// expandLayer 展开一层的所有局面，后继写成有序片段；遇到获胜局面时返回它的编码
+func (s *externalSearch) expandLayer(depth int) ([]byte, error) {
+	gs := s.gs
+	reader, err := openRecordReader(s.layerFile(depth), s.size)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.close()
+	s.buf = s.buf[:0]
+	var win []byte
+	for expanded := 0; reader.ok; expanded++ {
+		if expanded%1024 == 0 {
+			if err := gs.checkLimits(s.ctx, 0); err != nil {
+				return nil, err
+			}
+			s.progress.report(s.explored, s.queueSize+len(s.buf)/s.size, depth)
+		}
+		gs.decodeState(reader.record, gs.state.PieceList)
+		gs.gameState2Board(gs.state)
+		gs.forEachMove(func(pieceIndex int16) bool {
+			state := gs.encodeState(gs.state.PieceList)
+			if gs.isWin(gs.state) {
+				win = append([]byte{}, state...)
+				return true
+			}
+			s.buf = append(s.buf, state...)
+			gs.stats.Generated++
+			return false
+		})
+		if win != nil {
+			return win, nil
+		}
+		// 每个局面占用编码和一个4字节的排序索引
+		if len(s.buf)/s.size*(s.size+4) >= s.budget {
+			if err := s.flushRun(); err != nil {
+				return nil, err
+			}
+		}
+		if err := reader.next(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, s.flushRun()
+}
+
+// flushRun 把内存中的后继排序去重后写成一个片段
+func (s *externalSearch) flushRun() error {
+	count := len(s.buf) / s.size
+	if count == 0 {
+		return nil
+	}
+	index := make([]int32, count)
+	for i := range index {
+		index[i] = int32(i)
+	}
+	sort.Slice(index, func(i, j int) bool {
+		return bytes.Compare(s.record(index[i]), s.record(index[j])) < 0
+	})
+	file := filepath.Join(s.dir, fmt.Sprintf("run-%06d.tmp", len(s.runs)))
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	var last []byte
+	for _, i := range index {
+		record := s.record(i)
+		if last != nil && bytes.Equal(last, record) {
+			continue
+		}
+		last = record
+		if _, err := w.Write(record); err != nil {
+			f.Close()
+			return err
+		}
+		s.queueSize++
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	s.runs = append(s.runs, file)
+	s.buf = s.buf[:0]
+	return nil
+}
+
+func (s *externalSearch) record(i int32) []byte {
+	return s.buf[int(i)*s.size : int(i+1)*s.size]
+}
+
+// mergeLayer 归并本层的所有片段，去掉重复以及上一层、上上层已有的局面，写成新的一层
+func (s *externalSearch) mergeLayer(depth int) (int, error) {
+	s.queueSize = 0
+	runs := &recordHeap{}
+	defer func() {
+		for _, reader := range *runs {
+			reader.close()
+		}
+	}()
+	for _, file := range s.runs {
+		reader, err := openRecordReader(file, s.size)
+		if err != nil {
+			return 0, err
+		}
+		if reader.ok {
+			*runs = append(*runs, reader)
+		} else {
+			reader.close()
+		}
+	}
+	heap.Init(runs)
+
+	var prevLayers []*recordReader
+	for d := depth - 2; d <= depth-1; d++ {
+		if d < 0 {
+			continue
+		}
+		reader, err := openRecordReader(s.layerFile(d), s.size)
+		if err != nil {
+			return 0, err
+		}
+		defer reader.close()
+		prevLayers = append(prevLayers, reader)
+	}
+
+	file := s.layerFile(depth) + ".tmp"
+	f, err := os.Create(file)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(f)
+	count := 0
+	last := make([]byte, 0, s.size)
+	for runs.Len() > 0 {
+		if count%1024 == 0 {
+			if err := s.gs.checkLimits(s.ctx, 0); err != nil {
+				f.Close()
+				return 0, err
+			}
+		}
+		reader := (*runs)[0]
+		record := reader.record
+		duplicate := len(last) > 0 && bytes.Equal(last, record)
+		for _, prev := range prevLayers {
+			if duplicate {
+				break
+			}
+			duplicate, err = prev.seek(record)
+			if err != nil {
+				f.Close()
+				return 0, err
+			}
+		}
+		if !duplicate {
+			if _, err := w.Write(record); err != nil {
+				f.Close()
+				return 0, err
+			}
+			count++
+		}
+		last = append(last[:0], record...)
+		if err := reader.next(); err != nil {
+			f.Close()
+			return 0, err
+		}
+		if reader.ok {
+			heap.Fix(runs, 0)
+		} else {
+			heap.Pop(runs)
+			reader.close()
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	return count, os.Rename(file, s.layerFile(depth))
+}
+
+// path 获胜局面由第depth层展开得到，从它逐层向前，在每层的有序文件中二分查找一个相邻的局面，还原开局到获胜局面的路线
+func (s *externalSearch) path(depth int, win []byte) ([][]byte, error) {
+	gs := s.gs
+	states := make([][]byte, depth+2)
+	states[depth+1] = win
+	for d := depth; d >= 1; d-- {
+		f, err := os.Open(s.layerFile(d))
+		if err != nil {
+			return nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		count := int(info.Size()) / s.size
+		gs.decodeState(states[d+1], gs.state.PieceList)
+		gs.gameState2Board(gs.state)
+		var neighbors [][]byte
+		gs.forEachMove(func(pieceIndex int16) bool {
+			neighbors = append(neighbors, append([]byte{}, gs.encodeState(gs.state.PieceList)...))
+			return false
+		})
+		record := make([]byte, s.size)
+		for _, neighbor := range neighbors {
+			var readErr error
+			i := sort.Search(count, func(i int) bool {
+				if _, err := f.ReadAt(record, int64(i*s.size)); err != nil {
+					readErr = err
+					return true
+				}
+				return bytes.Compare(record, neighbor) >= 0
+			})
+			if readErr != nil {
+				f.Close()
+				return nil, readErr
+			}
+			if i < count {
+				if _, err := f.ReadAt(record, int64(i*s.size)); err == nil && bytes.Equal(record, neighbor) {
+					states[d] = neighbor
+					break
+				}
+			}
+		}
+		f.Close()
+		if states[d] == nil {
+			return nil, errors.New("broken layer files")
+		}
+	}
+	return states[1:], nil
+}
+
+// puzzleKey 布局和计步方式的摘要，用于区分不同搜索的临时文件
+func (gs *GameSolve) puzzleKey() string {
+	h := sha1.New()
+	goal, _ := json.Marshal(gs.goal)
+	shapes := make([]string, len(gs.pieceKindShapeList))
+	for i, kindShape := range gs.pieceKindShapeList {
+		shapes[i] = shape2Str(kindShape.Shape)
+	}
+	fmt.Fprintf(h, "%d|%s|%d|%d|%s|%v|%s|%s|%d|", externalVersion, gs.metric, gs.boardRows, gs.boardCols,
+		strings.Join(shapes, ","), gs.kingWinPos, gs.doorPlacement, goal, len(gs.symmetries))
+	_ = binary.Write(h, binary.LittleEndian, gs.wallBoard)
+	h.Write(gs.store.get(0))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordReader 顺序读取定长局面的文件
+type recordReader struct {
+	file   *os.File
+	reader *bufio.Reader
+	record []byte
+	ok     bool // record是否有效
+}
+
+func openRecordReader(file string, size int) (*recordReader, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	reader := &recordReader{
+		file:   f,
+		reader: bufio.NewReader(f),
+		record: make([]byte, size),
+	}
+	if err := reader.next(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+func (r *recordReader) next() error {
+	_, err := io.ReadFull(r.reader, r.record)
+	if err == io.EOF {
+		r.ok = false
+		return nil
+	}
+	r.ok = err == nil
+	return err
+}
+
+// seek 向后读到不小于record的位置，返回是否存在相同的局面
+func (r *recordReader) seek(record []byte) (bool, error) {
+	for r.ok {
+		c := bytes.Compare(r.record, record)
+		if c == 0 {
+			return true, nil
+		}
+		if c > 0 {
+			return false, nil
+		}
+		if err := r.next(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func (r *recordReader) close() {
+	_ = r.file.Close()
+}
+
+// recordHeap 多路归并用的小顶堆
+type recordHeap []*recordReader
+
+func (h recordHeap) Len() int            { return len(h) }
+func (h recordHeap) Less(i, j int) bool  { return bytes.Compare(h[i].record, h[j].record) < 0 }
+func (h recordHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *recordHeap) Push(x interface{}) { *h = append(*h, x.(*recordReader)) }
+func (h *recordHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
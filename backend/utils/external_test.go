@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// countdownContext 检查若干次之后变为已取消，用于在搜索中途确定地中断
+type countdownContext struct {
+	context.Context
+	checks int32
+}
+
+func (c *countdownContext) Err() error {
+	if atomic.AddInt32(&c.checks, -1) < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func externalSolve(ctx context.Context, dir string) (SolveResult, error) {
+	gs := GameSolve{Options: SolveOptions{Metric: MetricPiece, Algorithm: AlgorithmExternal, ScratchDir: dir, MemoryBudget: 1}}
+	gs.Init(classicGame())
+	return gs.Solve(ctx)
+}
+
+// TestExternalResume 中断之后用同一临时目录再次求解，从检查点继续并得到同样的解法
+func TestExternalResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "external-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fresh, err := externalSolve(context.Background(), dir)
+	if err != nil || fresh.Length != 81 {
+		t.Fatalf("length %d, %v", fresh.Length, err)
+	}
+
+	_, err = externalSolve(&countdownContext{Context: context.Background(), checks: 30}, dir)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("interrupted solve: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*", externalCheckpoint))
+	if len(files) != 1 {
+		t.Fatalf("%d checkpoints", len(files))
+	}
+	data, _ := ioutil.ReadFile(files[0])
+	checkpoint := externalState{}
+	if err := json.Unmarshal(data, &checkpoint); err != nil || checkpoint.Depth == 0 {
+		t.Fatalf("checkpoint %+v, %v", checkpoint, err)
+	}
+
+	resumed, err := externalSolve(context.Background(), dir)
+	if err != nil || resumed.Length != fresh.Length {
+		t.Fatalf("resumed length %d, %v", resumed.Length, err)
+	}
+	if resumed.ForwardExplored != fresh.ForwardExplored || resumed.Stats.Generated >= fresh.Stats.Generated {
+		t.Errorf("resumed explored %d, generated %d; fresh explored %d, generated %d",
+			resumed.ForwardExplored, resumed.Stats.Generated, fresh.ForwardExplored, fresh.Stats.Generated)
+	}
+	verify, err := Verify(classicGame(), resumed.Steps)
+	if err != nil || !verify.Solved {
+		t.Errorf("resumed solution does not verify")
+	}
+}
+
+// TestExternalScratchDirBusy 同一布局的外存搜索正在进行时，第二个搜索被拒绝
+func TestExternalScratchDirBusy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "external-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	gs := GameSolve{Options: SolveOptions{Metric: MetricPiece}}
+	gs.Init(classicGame())
+	unlock, err := lockExternalDir(filepath.Join(dir, "custom-klotski-"+gs.puzzleKey()[:16]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := externalSolve(context.Background(), dir); !errors.Is(err, ErrScratchDirBusy) {
+		t.Errorf("got %v", err)
+	}
+	unlock()
+	if result, err := externalSolve(context.Background(), dir); err != nil || result.Length != 81 {
+		t.Errorf("after unlock: length %d, %v", result.Length, err)
+	}
+}
@@ -0,0 +1,279 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// GameShape2GameData 数据库的布局数据转换成布局，与前端 GameUtils.gameShape2GameData 相同
+// 布局数据是带一圈边缘的棋盘，-2为墙，-1为空格或出口，其他为棋子索引，王棋索引为0；
+// 没有获胜条件，需要获胜条件时使用 GameShapeGoal2GameData
+// 边缘以内的-2为棋盘内的墙，用于障碍和不规则的棋盘轮廓
+func GameShape2GameData(gameShape string) (GameData, error) {
+	game := GameData{}
+	var grids [][]int
+	if err := json.Unmarshal([]byte(gameShape), &grids); err != nil {
+		return game, err
+	}
+	if len(grids) < 3 || len(grids[0]) < 3 {
+		return game, errors.New("invalid game shape")
+	}
+	for _, row := range grids {
+		if len(row) != len(grids[0]) {
+			return game, errors.New("invalid game shape")
+		}
+	}
+	game.BoardRows = int16(len(grids) - 2)
+	game.BoardCols = int16(len(grids[0]) - 2)
+
+	// 每个棋子在棋盘上占据的格子范围，{索引:[最小行,最小列,最大行,最大列]}
+	var bounds [][]int
+	for i := 1; i < len(grids)-1; i++ {
+		for j := 1; j < len(grids[i])-1; j++ {
+			value := grids[i][j]
+			if value == -2 {
+				game.Walls = append(game.Walls, Pos{int16(i - 1), int16(j - 1)})
+			}
+			if value < 0 {
+				continue
+			}
+			for len(bounds) <= value {
+				bounds = append(bounds, nil)
+			}
+			if bounds[value] == nil {
+				bounds[value] = []int{i, j, i, j}
+				continue
+			}
+			bound := bounds[value]
+			bound[0], bound[1] = minInt(bound[0], i), minInt(bound[1], j)
+			bound[2], bound[3] = maxInt(bound[2], i), maxInt(bound[3], j)
+		}
+	}
+	if len(bounds) == 0 {
+		return game, errors.New("no pieces")
+	}
+	for value, bound := range bounds {
+		if bound == nil {
+			return game, errors.New("missing piece")
+		}
+		shape := make(Shape, bound[2]-bound[0]+1)
+		for r := range shape {
+			shape[r] = make([]bool, bound[3]-bound[1]+1)
+			for c := range shape[r] {
+				shape[r][c] = grids[bound[0]+r][bound[1]+c] == value
+			}
+		}
+		game.PieceList = append(game.PieceList, Piece{
+			Shape:    shape,
+			Position: Pos{int16(bound[0] - 1), int16(bound[1] - 1)},
+		})
+	}
+
+	// 在边缘上找出门的位置
+	rows, cols := len(grids), len(grids[0])
+	kingShape := game.PieceList[0].Shape
+	sides := []struct {
+		placement string
+		grids     []int
+	}{
+		{"top", grids[0][1 : cols-1]},
+		{"right", column(grids, cols-1)[1 : rows-1]},
+		{"bottom", grids[rows-1][1 : cols-1]},
+		{"left", column(grids, 0)[1 : rows-1]},
+	}
+	for _, side := range sides {
+		for i, value := range side.grids {
+			if value == -1 {
+				game.Door = Door{
+					Placement:  side.placement,
+					StartIndex: i,
+					XSize:      len(kingShape[0]),
+					YSize:      len(kingShape),
+				}
+				break
+			}
+		}
+		if game.Door.Placement != "" {
+			break
+		}
+	}
+	game.KingPieceIndex = 0
+	game.KingWinPos = kingWinPos(game.BoardRows, game.BoardCols, kingShape, game.Door)
+	return game, nil
+}
+
+// storedGoal 数据库中保存的获胜条件，棋子索引按布局数据中的顺序
+// 布局数据中总是把索引为0的棋子当作王棋，没有王棋的布局需要在获胜条件中记录
+type storedGoal struct {
+	Goal
+	NoKing bool `json:"noKing,omitempty"` // 没有王棋，布局数据中索引为0的棋子是普通棋子
+}
+
+// GameShapeGoal2GameData 数据库的布局数据和获胜条件转换成布局，获胜条件为空时王棋到达出口即获胜
+func GameShapeGoal2GameData(gameShape string, goal string) (GameData, error) {
+	game, err := GameShape2GameData(gameShape)
+	if err != nil || goal == "" {
+		return game, err
+	}
+	stored := storedGoal{}
+	if err := json.Unmarshal([]byte(goal), &stored); err != nil {
+		return game, err
+	}
+	game.Goal = &stored.Goal
+	if stored.NoKing {
+		game.KingPieceIndex = -1
+		game.KingWinPos = Pos{-1, -1}
+	}
+	return game, nil
+}
+
+// GameData2Goal 布局的获胜条件转换成保存到数据库的JSON，棋子索引按 GameData2GameShape 的顺序；
+// 没有获胜条件时为空
+func GameData2Goal(game GameData) string {
+	if game.Goal.isEmpty() {
+		return ""
+	}
+	game = KingFirst(game)
+	data, _ := json.Marshal(storedGoal{
+		Goal:   *game.Goal,
+		NoKing: game.KingPieceIndex < 0,
+	})
+	return string(data)
+}
+
+// KingFirst 把王棋移到索引0，其余棋子保持原来的顺序，即 GameData2GameShape 保存的顺序，
+// 获胜条件中的棋子索引和目标布局随之调整；没有王棋或王棋已经在最前面时原样返回
+func KingFirst(game GameData) GameData {
+	king := int(game.KingPieceIndex)
+	if king <= 0 || king >= len(game.PieceList) {
+		return game
+	}
+	// order 新顺序中每个位置原来的索引，newIndex 原索引在新顺序中的位置
+	order := []int{king}
+	for i := range game.PieceList {
+		if i != king {
+			order = append(order, i)
+		}
+	}
+	newIndex := make([]int16, len(order))
+	pieceList := make([]Piece, len(order))
+	for i, old := range order {
+		pieceList[i] = game.PieceList[old]
+		newIndex[old] = int16(i)
+	}
+	game.PieceList = pieceList
+	game.KingPieceIndex = 0
+	if game.Goal != nil {
+		goal := *game.Goal
+		if len(goal.Pieces) > 0 {
+			goal.Pieces = make([]PieceGoal, len(game.Goal.Pieces))
+			for i, pieceGoal := range game.Goal.Pieces {
+				if pieceGoal.PieceIndex >= 0 && int(pieceGoal.PieceIndex) < len(newIndex) {
+					pieceGoal.PieceIndex = newIndex[pieceGoal.PieceIndex]
+				}
+				goal.Pieces[i] = pieceGoal
+			}
+		}
+		if len(goal.Layout) == len(order) {
+			goal.Layout = make([]Pos, len(order))
+			for i, old := range order {
+				goal.Layout[i] = game.Goal.Layout[old]
+			}
+		}
+		game.Goal = &goal
+	}
+	return game
+}
+
+// GameData2GameShape 布局转换成数据库的布局数据，与前端 GameUtils.gameData2GameShape 相同
+// 棋子按 KingFirst 的顺序保存，获胜条件需要用 GameData2Goal 按同样的顺序保存；
+// 布局需要先通过 Validate 检查
+func GameData2GameShape(game GameData) string {
+	rows, cols := int(game.BoardRows)+2, int(game.BoardCols)+2
+	// 建立一个带有边缘的棋盘，边缘为墙，中间为空格
+	grids := make([][]int, rows)
+	for i := range grids {
+		grids[i] = make([]int, cols)
+		for j := range grids[i] {
+			if i == 0 || j == 0 || i == rows-1 || j == cols-1 {
+				grids[i][j] = -2
+			} else {
+				grids[i][j] = -1
+			}
+		}
+	}
+	for _, wall := range game.Walls {
+		grids[wall[0]+1][wall[1]+1] = -2
+	}
+	// 有获胜条件时可以没有王棋（索引为-1），此时保持原来的顺序
+	for index, piece := range KingFirst(game).PieceList {
+		for r, row := range piece.Shape {
+			for c, filled := range row {
+				if filled {
+					grids[int(piece.Position[0])+r+1][int(piece.Position[1])+c+1] = index
+				}
+			}
+		}
+	}
+
+	// 在棋盘边缘上挖出门
+	door := game.Door
+	switch door.Placement {
+	case "top":
+		for i := 0; i < door.XSize; i++ {
+			grids[0][door.StartIndex+i+1] = -1
+		}
+	case "bottom":
+		for i := 0; i < door.XSize; i++ {
+			grids[rows-1][door.StartIndex+i+1] = -1
+		}
+	case "left":
+		for i := 0; i < door.YSize; i++ {
+			grids[door.StartIndex+i+1][0] = -1
+		}
+	case "right":
+		for i := 0; i < door.YSize; i++ {
+			grids[door.StartIndex+i+1][cols-1] = -1
+		}
+	}
+	data, _ := json.Marshal(grids)
+	return string(data)
+}
+
+// kingWinPos 按出口位置计算王棋获胜时的位置，与前端 GameUtils.makeGameData 相同
+func kingWinPos(rows int16, cols int16, kingShape Shape, door Door) Pos {
+	startIndex := int16(door.StartIndex)
+	switch door.Placement {
+	case "top":
+		return Pos{0, startIndex}
+	case "right":
+		return Pos{startIndex, cols - int16(len(kingShape[0]))}
+	case "bottom":
+		return Pos{rows - int16(len(kingShape)), startIndex}
+	case "left":
+		return Pos{startIndex, 0}
+	}
+	return Pos{-1, -1}
+}
+
+func column(grids [][]int, col int) []int {
+	values := make([]int, len(grids))
+	for i, row := range grids {
+		values[i] = row[col]
+	}
+	return values
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
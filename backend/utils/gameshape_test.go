@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+// exportFile 导出文件中与布局有关的部分，格式与 app.ExportData 相同
+// testdata/baseline-export.json 是没有墙和获胜条件的旧版导出文件；
+// testdata/layouts.json 是按导出格式编写的带墙和获胜条件的布局
+type exportFile struct {
+	Games []struct {
+		Name      string `json:"name"`
+		GameShape string `json:"gameShape"`
+		Goal      string `json:"goal"`
+	} `json:"games"`
+}
+
+func loadExportFile(t *testing.T, filename string) exportFile {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := exportFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Games) == 0 {
+		t.Fatalf("%s: no games", filename)
+	}
+	return file
+}
+
+// TestGameShapeRoundTrip 导出文件中的布局数据解析之后再转换回去，应与原来的完全相同
+func TestGameShapeRoundTrip(t *testing.T) {
+	for _, filename := range []string{"testdata/baseline-export.json", "testdata/layouts.json"} {
+		file := loadExportFile(t, filename)
+		for _, game := range file.Games {
+			gameData, err := GameShapeGoal2GameData(game.GameShape, game.Goal)
+			if err != nil {
+				t.Errorf("%s: %v", game.Name, err)
+				continue
+			}
+			if errs := Validate(gameData); len(errs) > 0 {
+				t.Errorf("%s: validation errors %v", game.Name, errs)
+			}
+			if shape := GameData2GameShape(gameData); shape != game.GameShape {
+				t.Errorf("%s: round trip got %s, want %s", game.Name, shape, game.GameShape)
+			}
+		}
+	}
+}
+
+// TestGameShape2GameData 解析旧版导出的经典布局，与手写的布局相同
+func TestGameShape2GameData(t *testing.T) {
+	file := loadExportFile(t, "testdata/baseline-export.json")
+	gameData, err := GameShape2GameData(file.Games[0].GameShape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := classicGame()
+	if gameData.BoardRows != want.BoardRows || gameData.BoardCols != want.BoardCols || gameData.Door != want.Door ||
+		gameData.KingWinPos[0] != 3 || gameData.KingWinPos[1] != 1 || len(gameData.Walls) != 0 {
+		t.Errorf("got %+v", gameData)
+	}
+	if PuzzleHash(gameData) != PuzzleHash(want) {
+		t.Error("parsed classic layout differs from the original")
+	}
+
+	// 右边的门
+	gameData, err = GameShape2GameData(file.Games[2].GameShape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gameData.Door.Placement != "right" || gameData.Door.StartIndex != 2 || gameData.KingWinPos[0] != 2 || gameData.KingWinPos[1] != 3 {
+		t.Errorf("door %+v, king win position %v", gameData.Door, gameData.KingWinPos)
+	}
+
+	for _, gameShape := range []string{
+		"",
+		"[[-2,-2],[-2,-2]]",                  // 太小
+		"[[-2,-2,-2],[-2,0,-2],[-2,-2]]",     // 行的长度不同
+		"[[-2,-2,-2],[-2,-1,-2],[-2,-2,-2]]", // 没有棋子
+		"[[-2,-2,-2,-2],[-2,0,2,-2],[-2,-2,-2,-2]]", // 缺少索引为1的棋子
+	} {
+		if _, err := GameShape2GameData(gameShape); err == nil {
+			t.Errorf("%q: expected an error", gameShape)
+		}
+	}
+}
+
+// TestGameShapeKingFirst 王棋不是第一枚棋子时，转换后王棋的索引为0，其余棋子顺序不变
+func TestGameShapeKingFirst(t *testing.T) {
+	game := classicGame()
+	want := GameData2GameShape(game)
+	game.PieceList = append(game.PieceList[1:], game.PieceList[0])
+	game.KingPieceIndex = int16(len(game.PieceList) - 1)
+	if shape := GameData2GameShape(game); shape != want {
+		t.Errorf("got %s, want %s", shape, want)
+	}
+}
+
+// TestGameShapeNoKing 有获胜条件而没有王棋时保持棋子顺序
+func TestGameShapeNoKing(t *testing.T) {
+	game := GameData{
+		BoardRows:      1,
+		BoardCols:      3,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 2}}, {Shape{{true}}, Pos{0, 0}}},
+		Goal:           &Goal{Pieces: []PieceGoal{{PieceIndex: 1, Position: Pos{0, 1}}}},
+	}
+	if errs := Validate(game); len(errs) > 0 {
+		t.Fatalf("validation errors %v", errs)
+	}
+	want := "[[-2,-2,-2,-2,-2],[-2,1,-1,0,-2],[-2,-2,-2,-2,-2]]"
+	if shape := GameData2GameShape(game); shape != want {
+		t.Errorf("got %s, want %s", shape, want)
+	}
+}
+
+// TestGameShapeGoalRoundTrip 布局保存成布局数据和获胜条件再读回来，谜题不变
+// 王棋不在最前面时获胜条件中的棋子索引和目标布局随棋子一起调整，没有王棋时读回来仍然没有王棋
+func TestGameShapeGoalRoundTrip(t *testing.T) {
+	kingThird := classicGame()
+	kingThird.PieceList[0], kingThird.PieceList[2] = kingThird.PieceList[2], kingThird.PieceList[0]
+	kingThird.KingPieceIndex = 2
+	kingThird.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 3}}}}
+
+	solved, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	layoutGoal := classicGame()
+	layoutGoal.Goal = &Goal{Layout: layoutAfter(t, layoutGoal, solved.Steps, 12)}
+	layoutGoal.PieceList[0], layoutGoal.PieceList[5] = layoutGoal.PieceList[5], layoutGoal.PieceList[0]
+	layoutGoal.Goal.Layout[0], layoutGoal.Goal.Layout[5] = layoutGoal.Goal.Layout[5], layoutGoal.Goal.Layout[0]
+	layoutGoal.KingPieceIndex = 5
+
+	noKing := GameData{
+		BoardRows:      1,
+		BoardCols:      3,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 2}}, {Shape{{true}}, Pos{0, 0}}},
+		Goal:           &Goal{Pieces: []PieceGoal{{PieceIndex: 1, Position: Pos{0, 1}}}},
+	}
+
+	cases := []struct {
+		name string
+		game GameData
+		king int16
+	}{
+		{"king at index 2 with a piece goal", kingThird, 0},
+		{"king at index 5 with a layout goal", layoutGoal, 0},
+		{"no king", noKing, -1},
+	}
+	for _, c := range cases {
+		if errs := Validate(c.game); len(errs) > 0 {
+			t.Fatalf("%s: validation errors %v", c.name, errs)
+		}
+		loaded, err := GameShapeGoal2GameData(GameData2GameShape(c.game), GameData2Goal(c.game))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if loaded.KingPieceIndex != c.king {
+			t.Errorf("%s: king index %d, want %d", c.name, loaded.KingPieceIndex, c.king)
+		}
+		if PuzzleHash(loaded) != PuzzleHash(c.game) {
+			t.Errorf("%s: hash %s, want %s", c.name, PuzzleHash(loaded), PuzzleHash(c.game))
+		}
+		if first := KingFirst(c.game); PuzzleHash(first) != PuzzleHash(c.game) || GameData2GameShape(first) != GameData2GameShape(c.game) {
+			t.Errorf("%s: king first order changes the puzzle", c.name)
+		}
+	}
+
+	// 获胜条件指向的棋子跟着棋子移动
+	first := KingFirst(kingThird)
+	if first.Goal.Pieces[0].PieceIndex != 1 || shape2Str(first.PieceList[1].Shape) != shape2Str(kingThird.PieceList[0].Shape) {
+		t.Errorf("goal piece index %d", first.Goal.Pieces[0].PieceIndex)
+	}
+	if kingThird.Goal.Pieces[0].PieceIndex != 0 {
+		t.Error("KingFirst changed the original goal")
+	}
+}
@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ErrGenerateTimeout 在时间限制内没有生成符合要求的布局
+var ErrGenerateTimeout = errors.New("generate timeout")
+
+// ErrInvalidOptions 生成选项不合法，例如形状不是矩形或出口不在棋盘边上
+var ErrInvalidOptions = errors.New("invalid options")
+
+const (
+	defaultGenerateEmptyCells = 2               // 默认留出的空格数
+	defaultGenerateTimeBudget = 5 * time.Second // 默认的时间限制
+)
+
+// GenerateOptions 随机生成布局的选项
+type GenerateOptions struct {
+	BoardRows  int16   `json:"boardRows"`
+	BoardCols  int16   `json:"boardCols"`
+	Door       Door    `json:"door"`       // 出口，只需要位置和起始序号，大小按王棋形状计算
+	KingShape  Shape   `json:"kingShape"`  // 王棋形状
+	ShapePool  []Shape `json:"shapePool"`  // 可以使用的其他棋子形状，重复出现的形状被选中的机会更大
+	EmptyCells int     `json:"emptyCells"` // 留出的空格数，默认为2
+	MinLength  int     `json:"minLength"`  // 最优解长度的下限
+	MaxLength  int     `json:"maxLength"`  // 最优解长度的上限，不大于0时不限
+	Metric     Metric  `json:"metric"`
+	MaxStates  int     `json:"maxStates"`  // 每次尝试最多穷举的局面数，超出时放弃这次尝试
+	MaxMemory  int     `json:"maxMemory"`  // 每次尝试的内存上限，MB，超出时放弃这次尝试，为0时不限制
+	Seed       int64   `json:"seed"`       // 随机种子，相同的选项和种子生成相同的布局
+	TimeBudget int     `json:"timeBudget"` // 时间限制，毫秒，默认5秒
+}
+
+// GenerateResult 随机生成的布局
+type GenerateResult struct {
+	GameData GameData `json:"gameData"`
+	Metric   Metric   `json:"metric"`
+	Length   int      `json:"length"`   // 最优解长度
+	Attempts int      `json:"attempts"` // 随机摆放的次数
+	Seed     int64    `json:"seed"`
+}
+
+// Generate 随机摆放棋子，穷举摆放结果所在的连通分量，从中随机选一个最优解长度符合要求的局面作为开局
+// 选项不合法时返回 ErrInvalidOptions，超出时间限制时返回 ErrGenerateTimeout
+func Generate(ctx context.Context, options GenerateOptions) (GenerateResult, error) {
+	result := GenerateResult{
+		Metric: options.Metric,
+		Seed:   options.Seed,
+	}
+	if result.Metric == "" {
+		result.Metric = MetricPiece
+	}
+	if !validGenerateOptions(options) {
+		return result, ErrInvalidOptions
+	}
+	emptyCells := options.EmptyCells
+	if emptyCells <= 0 {
+		emptyCells = defaultGenerateEmptyCells
+	}
+	timeBudget := time.Duration(options.TimeBudget) * time.Millisecond
+	if timeBudget <= 0 {
+		timeBudget = defaultGenerateTimeBudget
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeBudget)
+	defer cancel()
+
+	rng := rand.New(rand.NewSource(options.Seed))
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, generateErr(err)
+		}
+		result.Attempts++
+		game, ok := randomGameData(rng, options, emptyCells)
+		if !ok {
+			continue
+		}
+		// 摆放结果不合法说明选项有误，再尝试也不会成功
+		if len(Validate(game)) > 0 {
+			return result, ErrInvalidOptions
+		}
+		gs := GameSolve{
+			Options: SolveOptions{
+				Metric:    options.Metric,
+				MaxStates: options.MaxStates,
+				MaxMemory: options.MaxMemory,
+			},
+		}
+		gs.Init(game)
+		if _, err := gs.Analyze(ctx); err != nil {
+			if errors.Is(err, ErrLimitExceeded) {
+				continue
+			}
+			return result, generateErr(err)
+		}
+		var candidates []int32
+		for index, d := range gs.distance {
+			if int(d) >= options.MinLength && d >= 0 && (options.MaxLength <= 0 || int(d) <= options.MaxLength) {
+				candidates = append(candidates, int32(index))
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		index := candidates[rng.Intn(len(candidates))]
+		result.GameData = gs.stateGameData(game, index)
+		result.Length = int(gs.distance[index])
+		return result, nil
+	}
+}
+
+// validGenerateOptions 检查棋盘、形状和出口，王棋在出口位置时必须完全在棋盘内
+func validGenerateOptions(options GenerateOptions) bool {
+	rows, cols := options.BoardRows, options.BoardCols
+	if rows <= 0 || cols <= 0 || int(rows)*int(cols) > maxBoardGrids || !validShape(options.KingShape) {
+		return false
+	}
+	for _, shape := range options.ShapePool {
+		if !validShape(shape) {
+			return false
+		}
+	}
+	if !validDoorPlacement(options.Door.Placement) {
+		return false
+	}
+	door := Door{
+		Placement:  options.Door.Placement,
+		StartIndex: options.Door.StartIndex,
+		XSize:      len(options.KingShape[0]),
+		YSize:      len(options.KingShape),
+	}
+	probe := GameData{BoardRows: rows, BoardCols: cols}
+	return shapeInBoard(probe, options.KingShape, kingWinPos(rows, cols, options.KingShape, door))
+}
+
+func generateErr(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrGenerateTimeout
+	}
+	return err
+}
+
+// randomGameData 随机摆放王棋和其他棋子，直到只剩下指定数量的空格，摆不满时返回false
+func randomGameData(rng *rand.Rand, options GenerateOptions, emptyCells int) (GameData, bool) {
+	rows, cols := options.BoardRows, options.BoardCols
+	game := GameData{
+		BoardRows:      rows,
+		BoardCols:      cols,
+		KingPieceIndex: 0,
+		Door: Door{
+			Placement:  options.Door.Placement,
+			StartIndex: options.Door.StartIndex,
+			XSize:      len(options.KingShape[0]),
+			YSize:      len(options.KingShape),
+		},
+	}
+	game.KingWinPos = kingWinPos(rows, cols, options.KingShape, game.Door)
+
+	board := make([]bool, int(rows)*int(cols))
+	free := len(board)
+	place := func(shape Shape) bool {
+		var positions []Pos
+		for row := int16(0); row+int16(len(shape)) <= rows; row++ {
+			for col := int16(0); col+int16(len(shape[0])) <= cols; col++ {
+				if shapeFits(board, cols, shape, row, col) {
+					positions = append(positions, Pos{row, col})
+				}
+			}
+		}
+		if len(positions) == 0 {
+			return false
+		}
+		pos := positions[rng.Intn(len(positions))]
+		for rowIndex, shapeRow := range shape {
+			for colIndex, grid := range shapeRow {
+				if grid {
+					board[(pos[0]+int16(rowIndex))*cols+pos[1]+int16(colIndex)] = true
+					free--
+				}
+			}
+		}
+		game.PieceList = append(game.PieceList, Piece{Shape: shape, Position: pos})
+		return true
+	}
+	if !place(options.KingShape) {
+		return game, false
+	}
+
+	// 每次从还放得下的形状中随机选一个
+	for free > emptyCells {
+		var fits []Shape
+		for _, shape := range options.ShapePool {
+			if shapeCellCount(shape) <= free-emptyCells {
+				fits = append(fits, shape)
+			}
+		}
+		placed := false
+		for len(fits) > 0 && !placed {
+			i := rng.Intn(len(fits))
+			placed = place(fits[i])
+			fits = append(fits[:i], fits[i+1:]...)
+		}
+		if !placed {
+			return game, false
+		}
+	}
+	return game, true
+}
+
+func shapeFits(board []bool, cols int16, shape Shape, row int16, col int16) bool {
+	for rowIndex, shapeRow := range shape {
+		for colIndex, grid := range shapeRow {
+			if grid && board[(row+int16(rowIndex))*cols+col+int16(colIndex)] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func shapeCellCount(shape Shape) int {
+	count := 0
+	for _, row := range shape {
+		for _, grid := range row {
+			if grid {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func validDoorPlacement(placement string) bool {
+	switch placement {
+	case "top", "right", "bottom", "left":
+		return true
+	}
+	return false
+}
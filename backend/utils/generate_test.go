@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func generateOptions(seed int64) GenerateOptions {
+	v := Shape{{true}, {true}}
+	h := Shape{{true, true}}
+	s := Shape{{true}}
+	return GenerateOptions{
+		BoardRows: 4,
+		BoardCols: 4,
+		Door:      Door{Placement: "bottom", StartIndex: 1},
+		KingShape: Shape{{true, true}, {true, true}},
+		ShapePool: []Shape{v, h, s, s},
+		MinLength: 8,
+		MaxLength: 12,
+		Seed:      seed,
+	}
+}
+
+// TestGenerateSeed 相同的种子生成相同的布局，最优解长度在要求的范围内
+func TestGenerateSeed(t *testing.T) {
+	first, err := Generate(context.Background(), generateOptions(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Length < 8 || first.Length > 12 {
+		t.Errorf("length %d out of range", first.Length)
+	}
+	second, err := Generate(context.Background(), generateOptions(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if GameData2GameShape(first.GameData) != GameData2GameShape(second.GameData) || first.Attempts != second.Attempts {
+		t.Errorf("same seed generated different games: %+v, %+v", first, second)
+	}
+
+	result, err := solveGame(t, first.GameData, SolveOptions{Metric: MetricPiece})
+	if err != nil || result.Length != first.Length {
+		t.Errorf("solved length %d, generated length %d, %v", result.Length, first.Length, err)
+	}
+}
+
+// TestGenerateInvalidOptions 不合法的选项立即返回错误，而不是崩溃或一直尝试到超时
+func TestGenerateInvalidOptions(t *testing.T) {
+	ragged := generateOptions(1)
+	ragged.ShapePool = append(ragged.ShapePool, Shape{{true}, {true, true}})
+	raggedKing := generateOptions(1)
+	raggedKing.KingShape = Shape{{true}, {true, true}}
+	doorOutside := generateOptions(1)
+	doorOutside.Door.StartIndex = 10
+	noDoor := generateOptions(1)
+	noDoor.Door.Placement = ""
+	for i, options := range []GenerateOptions{ragged, raggedKing, doorOutside, noDoor} {
+		if _, err := Generate(context.Background(), options); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("case %d: got %v", i, err)
+		}
+	}
+}
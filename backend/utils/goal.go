@@ -0,0 +1,121 @@
+package utils
+
+// Goal 获胜条件，给出的条件需要同时满足，全部为空时按王棋到达出口判断
+type Goal struct {
+	Pieces  []PieceGoal  `json:"pieces,omitempty"`  // 指定的棋子分别到达各自的位置
+	Layout  []Pos        `json:"layout,omitempty"`  // 所有棋子组成指定的布局，同形状的棋子可以互换
+	Regions []RegionGoal `json:"regions,omitempty"` // 某种形状的任意一枚棋子完全进入指定区域
+}
+
+// PieceGoal 一枚棋子的目标位置
+type PieceGoal struct {
+	PieceIndex int16 `json:"pieceIndex"`
+	Position   Pos   `json:"position"`
+}
+
+// RegionGoal 区域目标，Shape 形状的棋子中任意一枚占据的格子都在 Cells 中即满足
+type RegionGoal struct {
+	Shape Shape `json:"shape"`
+	Cells []Pos `json:"cells"`
+}
+
+func (goal *Goal) isEmpty() bool {
+	return goal == nil || len(goal.Pieces) == 0 && len(goal.Layout) == 0 && len(goal.Regions) == 0
+}
+
+// pieceGoal 展开后的棋子目标
+type pieceGoal struct {
+	pieceIndex int16
+	piece      int16
+}
+
+// regionGoal 展开后的区域目标
+type regionGoal struct {
+	mask   []bool  // 棋盘上属于区域的格子
+	pieces []int16 // 形状相同、可以满足目标的棋子
+}
+
+// goalPieceSet 有单独目标的棋子，与王棋一样不与同形状的棋子互换
+func goalPieceSet(game GameData) map[int16]bool {
+	set := map[int16]bool{}
+	if !game.Goal.isEmpty() {
+		for _, pieceGoal := range game.Goal.Pieces {
+			set[pieceGoal.PieceIndex] = true
+		}
+	}
+	return set
+}
+
+// initGoal 展开获胜条件，在棋子类型确定之后调用
+func (gs *GameSolve) initGoal(game GameData) {
+	if game.Goal.isEmpty() {
+		return
+	}
+	gs.goal = game.Goal
+	pieceCount := len(gs.startPieceList)
+	if len(gs.goal.Layout) == pieceCount {
+		targetPieceList := make([]int16, pieceCount)
+		for i, pos := range gs.goal.Layout {
+			targetPieceList[i] = gs.posToPiece(pos)
+		}
+		gs.targetState = append([]byte{}, gs.encodeState(targetPieceList)...)
+		gs.targetPieceList = targetPieceList
+		gs.canonicalize(gs.targetPieceList)
+	}
+	for _, goal := range gs.goal.Pieces {
+		gs.pieceGoals = append(gs.pieceGoals, pieceGoal{
+			pieceIndex: goal.PieceIndex,
+			piece:      gs.posToPiece(goal.Position),
+		})
+	}
+	for _, goal := range gs.goal.Regions {
+		region := regionGoal{
+			mask: make([]bool, int(gs.boardRows)*int(gs.boardCols)),
+		}
+		for _, cell := range goal.Cells {
+			region.mask[gs.posToPiece(cell)] = true
+		}
+		shapeStr := shape2Str(goal.Shape)
+		for i, kindShape := range gs.pieceKindShapeList {
+			if shape2Str(kindShape.Shape) == shapeStr {
+				region.pieces = append(region.pieces, int16(i))
+			}
+		}
+		gs.regionGoals = append(gs.regionGoals, region)
+	}
+}
+
+// isGoal 局面是否满足获胜条件中的所有目标
+func (gs *GameSolve) isGoal(gameState GameState) bool {
+	if gs.targetState != nil && string(gs.encodeState(gameState.PieceList)) != string(gs.targetState) {
+		return false
+	}
+	for _, goal := range gs.pieceGoals {
+		if gameState.PieceList[goal.pieceIndex] != goal.piece {
+			return false
+		}
+	}
+	for _, region := range gs.regionGoals {
+		if !gs.inRegion(gameState, region) {
+			return false
+		}
+	}
+	return true
+}
+
+func (gs *GameSolve) inRegion(gameState GameState, region regionGoal) bool {
+	for _, pieceIndex := range region.pieces {
+		pos := gs.pieceToPos(gameState.PieceList[pieceIndex])
+		inside := true
+		for _, cell := range gs.pieceKindShapeList[pieceIndex].Cells {
+			if !region.mask[(pos[0]+cell[0])*gs.boardCols+pos[1]+cell[1]] {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
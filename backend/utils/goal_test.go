@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+// lineGame 1行n列的棋盘，一枚单格棋子在最左边
+func lineGame(cols int16) GameData {
+	return GameData{
+		BoardRows:      1,
+		BoardCols:      cols,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 0}}},
+	}
+}
+
+func solveGame(t *testing.T, game GameData, options SolveOptions) (SolveResult, error) {
+	t.Helper()
+	if errs := Validate(game); len(errs) > 0 {
+		t.Fatalf("validation errors %v", errs)
+	}
+	gs := GameSolve{Options: options}
+	gs.Init(game)
+	return gs.Solve(context.Background())
+}
+
+// TestPieceGoal 王棋到出口位置的棋子目标与默认的获胜条件等价
+func TestPieceGoal(t *testing.T) {
+	game := classicGame()
+	game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{3, 1}}}}
+	result, err := solveGame(t, game, SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Length != 81 {
+		t.Errorf("length %d, want 81", result.Length)
+	}
+	verify, err := Verify(game, result.Steps)
+	if err != nil || !verify.Solved {
+		t.Errorf("solution does not verify: %+v %v", verify, err)
+	}
+}
+
+// TestRegionGoal 任意一枚同形状的棋子进入区域即可
+func TestRegionGoal(t *testing.T) {
+	game := lineGame(4)
+	game.Goal = &Goal{Regions: []RegionGoal{{Shape: Shape{{true}}, Cells: []Pos{{0, 2}, {0, 3}}}}}
+	cases := []struct {
+		metric Metric
+		length int
+	}{
+		{MetricStep, 2},
+		{MetricStraight, 1},
+		{MetricPiece, 1},
+	}
+	for _, c := range cases {
+		result, err := solveGame(t, game, SolveOptions{Metric: c.metric})
+		if err != nil {
+			t.Errorf("%s: %v", c.metric, err)
+			continue
+		}
+		if result.Length != c.length {
+			t.Errorf("%s: length %d, want %d", c.metric, result.Length, c.length)
+		}
+	}
+}
+
+// TestCombinedGoals 目标布局与其他目标同时给出时，双向搜索也要满足所有目标
+func TestCombinedGoals(t *testing.T) {
+	game := lineGame(3)
+	// 目标布局与棋子目标矛盾，无解
+	game.Goal = &Goal{
+		Layout: []Pos{{0, 1}},
+		Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 2}}},
+	}
+	for _, algorithm := range []Algorithm{AlgorithmBFS, AlgorithmBidirectional} {
+		result, err := solveGame(t, game, SolveOptions{Metric: MetricPiece, Algorithm: algorithm})
+		if err == nil {
+			t.Errorf("%s: contradictory goals solved in %d moves", algorithm, result.Length)
+		}
+	}
+
+	// 目标一致时与单向搜索的结果相同
+	game.Goal.Pieces[0].Position = Pos{0, 1}
+	for _, algorithm := range []Algorithm{AlgorithmBFS, AlgorithmBidirectional} {
+		result, err := solveGame(t, game, SolveOptions{Metric: MetricStep, Algorithm: algorithm})
+		if err != nil || result.Length != 1 {
+			t.Errorf("%s: length %d, %v", algorithm, result.Length, err)
+		}
+	}
+}
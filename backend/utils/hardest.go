@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"errors"
+)
+
+// HardestResult 最难开局的查找结果
+type HardestResult struct {
+	GameData GameData `json:"gameData"` // 最难的开局，棋子形状、出口等与原布局相同
+	Metric   Metric   `json:"metric"`
+	Length   int      `json:"length"` // 最难开局的最优解长度
+	Ties     int      `json:"ties"`   // 同样最难的局面数
+}
+
+// FindHardest 在原布局可以到达的所有局面中，找出距目标最远的一个作为新的开局
+// 需要传入 Init 时使用的布局，新开局沿用其中的棋子形状、出口和目标
+func (gs *GameSolve) FindHardest(ctx context.Context, game GameData) (HardestResult, error) {
+	result := HardestResult{
+		Metric: gs.metric,
+	}
+	analysis, err := gs.Analyze(ctx)
+	if err != nil {
+		return result, err
+	}
+	if analysis.StartDistance < 0 {
+		return result, errors.New("no solution")
+	}
+	result.Length = analysis.MaxDistance
+	result.Ties = analysis.Histogram[analysis.MaxDistance]
+
+	// 仓库按广度优先的顺序存放，取第一个即离原开局最近的最难局面
+	hardest := int32(-1)
+	for index, d := range gs.distance {
+		if int(d) == analysis.MaxDistance {
+			hardest = int32(index)
+			break
+		}
+	}
+	result.GameData = gs.stateGameData(game, hardest)
+	return result, nil
+}
+
+// stateGameData 把仓库中的局面还原为布局，棋子形状、出口等沿用原布局
+// 规范化局面中同类棋子的顺序可能改变，但同类棋子形状相同，按索引放回即可
+func (gs *GameSolve) stateGameData(game GameData, index int32) GameData {
+	pieceList := make([]int16, len(gs.startPieceList))
+	gs.decodeState(gs.store.get(index), pieceList)
+	game.PieceList = append([]Piece{}, game.PieceList...)
+	for i, piece := range game.PieceList {
+		game.PieceList[i] = Piece{
+			Shape:    piece.Shape,
+			Position: gs.pieceToPos(pieceList[i]),
+		}
+	}
+	return game
+}
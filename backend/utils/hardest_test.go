@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+// TestFindHardest 最难开局的最优解长度等于可达局面中的最远距离，保存的布局可以直接求解
+func TestFindHardest(t *testing.T) {
+	for _, metric := range []Metric{MetricStraight, MetricPiece} {
+		analysis := analyzeGame(t, classicGame(), SolveOptions{Metric: metric})
+		gs := GameSolve{Options: SolveOptions{Metric: metric}}
+		gs.Init(classicGame())
+		hardest, err := gs.FindHardest(context.Background(), classicGame())
+		if err != nil {
+			t.Fatalf("%s: %v", metric, err)
+		}
+		if hardest.Length != analysis.MaxDistance || hardest.Ties != analysis.Histogram[analysis.MaxDistance] || hardest.Length <= analysis.StartDistance {
+			t.Errorf("%s: length %d, ties %d, max distance %d", metric, hardest.Length, hardest.Ties, analysis.MaxDistance)
+		}
+
+		game := hardest.GameData
+		if game.Door != classicGame().Door || game.KingPieceIndex != 0 || len(game.PieceList) != len(classicGame().PieceList) {
+			t.Errorf("%s: layout %+v", metric, game)
+		}
+		result, err := solveGame(t, game, SolveOptions{Metric: metric})
+		if err != nil || result.Length != hardest.Length {
+			t.Errorf("%s: hardest start solves in %d, want %d, %v", metric, result.Length, hardest.Length, err)
+		}
+	}
+}
+
+// TestFindHardestUnsolvable 无解的布局没有最难开局
+func TestFindHardestUnsolvable(t *testing.T) {
+	game := lineGame(3)
+	game.PieceList = append(game.PieceList, Piece{Shape{{true}}, Pos{0, 1}})
+	game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 2}}}}
+	gs := GameSolve{}
+	gs.Init(game)
+	if _, err := gs.FindHardest(context.Background(), game); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// PuzzleHashVersion 布局摘要算法的版本，算法改变时加一，摘要以版本号开头
+const PuzzleHashVersion = 2
+
+// hashPiece 摘要中的一枚棋子，棋子按内容排序，同形状的棋子互换顺序不影响摘要
+type hashPiece struct {
+	Shape    string `json:"shape"`
+	Position Pos    `json:"position"`
+	King     bool   `json:"king,omitempty"`
+	Target   Pos    `json:"target,omitempty"` // 单独的目标位置
+	Layout   Pos    `json:"layout,omitempty"` // 王棋和有单独目标的棋子在目标布局中的位置
+}
+
+// hashPuzzle 摘要的内容，只包含影响求解的部分
+type hashPuzzle struct {
+	Rows       int16       `json:"rows"`
+	Cols       int16       `json:"cols"`
+	Walls      []Pos       `json:"walls"`
+	Pieces     []hashPiece `json:"pieces"`
+	KingWinPos Pos         `json:"kingWinPos,omitempty"` // 没有获胜条件时王棋的出口位置
+	Door       string      `json:"door,omitempty"`       // 没有获胜条件时出口的方向
+	Layouts    []string    `json:"layouts,omitempty"`    // 可以互换的棋子在目标布局中的位置，每种形状一项
+	Regions    []string    `json:"regions,omitempty"`
+}
+
+// PuzzleHash 布局的规范摘要
+// 只由棋盘、墙、棋子和获胜条件决定，同形状的棋子在列表中的顺序不同时摘要相同；
+// 形如 "v2:" 加40位十六进制，布局需要先通过 Validate 检查
+func PuzzleHash(game GameData) string {
+	puzzle := hashPuzzle{
+		Rows:   game.BoardRows,
+		Cols:   game.BoardCols,
+		Walls:  append([]Pos{}, game.Walls...),
+		Pieces: make([]hashPiece, len(game.PieceList)),
+	}
+	sortPos(puzzle.Walls)
+	for i, piece := range game.PieceList {
+		puzzle.Pieces[i] = hashPiece{
+			Shape:    shape2Str(piece.Shape),
+			Position: piece.Position,
+			King:     int(game.KingPieceIndex) == i,
+		}
+	}
+	if game.Goal.isEmpty() {
+		puzzle.KingWinPos = game.KingWinPos
+		puzzle.Door = game.Door.Placement
+	} else {
+		for _, pieceGoal := range game.Goal.Pieces {
+			puzzle.Pieces[pieceGoal.PieceIndex].Target = pieceGoal.Position
+		}
+		if len(game.Goal.Layout) == len(game.PieceList) {
+			// 同形状的棋子在目标布局中可以互换，只记录每种形状的目标位置集合
+			layouts := map[string][]Pos{}
+			for i, pos := range game.Goal.Layout {
+				if puzzle.Pieces[i].King || puzzle.Pieces[i].Target != nil {
+					puzzle.Pieces[i].Layout = pos
+				} else {
+					layouts[puzzle.Pieces[i].Shape] = append(layouts[puzzle.Pieces[i].Shape], pos)
+				}
+			}
+			for shape, cells := range layouts {
+				sortPos(cells)
+				puzzle.Layouts = append(puzzle.Layouts, fmt.Sprintf("%s%v", shape, cells))
+			}
+			sort.Strings(puzzle.Layouts)
+		}
+		for _, region := range game.Goal.Regions {
+			cells := append([]Pos{}, region.Cells...)
+			sortPos(cells)
+			puzzle.Regions = append(puzzle.Regions, fmt.Sprintf("%s%v", shape2Str(region.Shape), cells))
+		}
+		sort.Strings(puzzle.Regions)
+	}
+	sort.Slice(puzzle.Pieces, func(i, j int) bool {
+		a, _ := json.Marshal(puzzle.Pieces[i])
+		b, _ := json.Marshal(puzzle.Pieces[j])
+		return string(a) < string(b)
+	})
+	data, _ := json.Marshal(puzzle)
+	sum := sha1.Sum(data)
+	return fmt.Sprintf("v%d:%s", PuzzleHashVersion, hex.EncodeToString(sum[:]))
+}
+
+// PuzzleMd5 保存在 Game.Md5 中用于查重的摘要，是 PuzzleHash 的md5，算法版本另外保存
+func PuzzleMd5(game GameData) string {
+	sum := md5.Sum([]byte(PuzzleHash(game)))
+	return hex.EncodeToString(sum[:])
+}
+
+func sortPos(list []Pos) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i][0] != list[j][0] {
+			return list[i][0] < list[j][0]
+		}
+		return list[i][1] < list[j][1]
+	})
+}
@@ -0,0 +1,50 @@
+package utils
+
+import "testing"
+
+// TestPuzzleHashIdenticalPieces 同形状的棋子互换顺序或互换目标布局中的位置，摘要不变
+func TestPuzzleHashIdenticalPieces(t *testing.T) {
+	game := classicGame()
+	hash := PuzzleHash(game)
+	if len(hash) != 43 || hash[:3] != "v2:" {
+		t.Fatalf("hash %s", hash)
+	}
+
+	swapped := classicGame()
+	swapped.PieceList[1], swapped.PieceList[4] = swapped.PieceList[4], swapped.PieceList[1]
+	swapped.PieceList[6], swapped.PieceList[9] = swapped.PieceList[9], swapped.PieceList[6]
+	if PuzzleHash(swapped) != hash {
+		t.Error("swapping identical pieces changes the hash")
+	}
+	if PuzzleMd5(swapped) != PuzzleMd5(game) {
+		t.Error("swapping identical pieces changes the md5")
+	}
+
+	// 王棋不能和其他棋子互换，移动一枚棋子后摘要改变
+	moved := classicGame()
+	moved.PieceList[9].Position = Pos{4, 2}
+	if PuzzleHash(moved) == hash {
+		t.Error("moving a piece keeps the hash")
+	}
+
+	layout := func(game GameData) GameData {
+		game.Goal = &Goal{Layout: []Pos{{3, 1}, {0, 0}, {0, 3}, {2, 0}, {2, 3}, {2, 1}, {0, 1}, {0, 2}, {1, 1}, {1, 2}}}
+		return game
+	}
+	layoutHash := PuzzleHash(layout(classicGame()))
+	if layoutHash == hash {
+		t.Error("a layout goal keeps the hash")
+	}
+	// 两枚竖棋互换目标位置
+	swappedLayout := layout(classicGame())
+	swappedLayout.Goal.Layout[1], swappedLayout.Goal.Layout[3] = swappedLayout.Goal.Layout[3], swappedLayout.Goal.Layout[1]
+	if PuzzleHash(swappedLayout) != layoutHash {
+		t.Error("swapping layout targets of identical pieces changes the hash")
+	}
+	// 王棋与单格棋子互换目标位置是不同的布局
+	otherLayout := layout(classicGame())
+	otherLayout.Goal.Layout[0], otherLayout.Goal.Layout[6] = otherLayout.Goal.Layout[6], otherLayout.Goal.Layout[0]
+	if PuzzleHash(otherLayout) == layoutHash {
+		t.Error("moving the king's layout target keeps the hash")
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+// Heuristic 启发函数
+type Heuristic struct {
+	Estimate   func(gs *GameSolve) int // 估计当前局面(gs.state)到达目标至少还需要的步数
+	Admissible bool                    // 是否保证不高估，不高估时A*和IDA*求出的是最优解
+}
+
+const defaultHeuristic = "goal"
+
+// heuristicMap 可选的启发函数，{名称:启发函数}
+var heuristicMap = map[string]Heuristic{
+	// 王棋到出口的距离，加上占据出口位置的棋子数
+	"goal": {
+		Estimate:   goalHeuristic,
+		Admissible: true,
+	},
+	// 不做估计，A*退化为一致代价搜索
+	"zero": {
+		Estimate: func(gs *GameSolve) int {
+			return 0
+		},
+		Admissible: true,
+	},
+	// 放大估计值，搜索更快，但不保证最优
+	"weighted": {
+		Estimate: func(gs *GameSolve) int {
+			return goalHeuristic(gs) * 3
+		},
+		Admissible: false,
+	},
+}
+
+// goalHeuristic 有目标布局时，累加每个棋子到最近的同类目标位置的距离；
+// 有其他获胜条件时，累加有单独目标的棋子到目标位置的距离，区域目标不做估计；
+// 否则为王棋到出口位置的距离，加上占据王棋出口位置的其他棋子数，这些棋子每个至少要移动一次
+func goalHeuristic(gs *GameSolve) int {
+	pieceList := gs.state.PieceList
+	if gs.targetState != nil {
+		estimate := 0
+		for _, group := range gs.kindGroups {
+			for _, i := range group {
+				minDistance := -1
+				for _, j := range group {
+					distance := gs.moveDistance(pieceList[i], gs.targetPieceList[j])
+					if minDistance < 0 || distance < minDistance {
+						minDistance = distance
+					}
+				}
+				estimate += minDistance
+			}
+		}
+		return estimate
+	}
+	if gs.goal != nil {
+		estimate := 0
+		for _, goal := range gs.pieceGoals {
+			estimate += gs.moveDistance(pieceList[goal.pieceIndex], goal.piece)
+		}
+		return estimate
+	}
+
+	estimate := gs.moveDistance(pieceList[gs.kingIndex], gs.posToPiece(gs.kingWinPos))
+	for i, cell := range gs.kingWinCells {
+		grid := gs.state.Board[cell]
+		if grid <= 0 || grid-1 == gs.kingIndex {
+			continue
+		}
+		// 同一枚棋子占据多格时只算一次
+		counted := false
+		for _, prevCell := range gs.kingWinCells[:i] {
+			if gs.state.Board[prevCell] == grid {
+				counted = true
+				break
+			}
+		}
+		if !counted {
+			estimate++
+		}
+	}
+	return estimate
+}
+
+// moveDistance 按计步方式，一枚棋子从一个位置到另一个位置至少需要的步数
+func (gs *GameSolve) moveDistance(from int16, to int16) int {
+	if from == to {
+		return 0
+	}
+	rowDiff := from/gs.boardCols - to/gs.boardCols
+	colDiff := from%gs.boardCols - to%gs.boardCols
+	switch gs.metric {
+	case MetricStep:
+		return int(abs16(rowDiff) + abs16(colDiff))
+	case MetricStraight:
+		distance := 0
+		if rowDiff != 0 {
+			distance++
+		}
+		if colDiff != 0 {
+			distance++
+		}
+		return distance
+	default:
+		return 1
+	}
+}
+
+func abs16(v int16) int16 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
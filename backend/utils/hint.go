@@ -0,0 +1,60 @@
+package utils
+
+import "errors"
+
+// ErrUnknownPosition 局面不在分析过的范围内，无法从开局到达
+var ErrUnknownPosition = errors.New("unknown position")
+
+// HintResult 提示
+type HintResult struct {
+	Metric   Metric `json:"metric"`
+	Solvable bool   `json:"solvable"` // 当前局面能否到达目标
+	Distance int    `json:"distance"` // 到达目标最少还需要的步数，已经获胜时为0
+	Step     Step   `json:"step"`     // 最优路线上的下一步，已经获胜或无法到达目标时为空
+}
+
+// Hint 根据分析得到的距离，给出当前局面最优路线上的下一步
+// 需要先调用 Analyze，局面不在开局所在的连通分量中时返回 ErrUnknownPosition
+func (gs *GameSolve) Hint(positions []Pos) (HintResult, error) {
+	result := HintResult{
+		Metric: gs.metric,
+		Step: Step{
+			PieceIndex: -1,
+			Direction:  []int16{0, 0},
+		},
+	}
+	if gs.distance == nil {
+		return result, errors.New("not analyzed")
+	}
+	if !gs.SetPositions(positions) {
+		return result, errors.New("invalid positions")
+	}
+	index := gs.store.find(gs.encodeState(gs.state.PieceList))
+	if index < 0 || int(index) >= len(gs.distance) {
+		return result, ErrUnknownPosition
+	}
+	distance := gs.distance[index]
+	if distance <= 0 {
+		result.Solvable = distance == 0
+		return result, nil
+	}
+	result.Solvable = true
+	result.Distance = int(distance)
+
+	from := append([]int16{}, gs.state.PieceList...)
+	gs.forEachMove(func(pieceIndex int16) bool {
+		next := gs.store.find(gs.encodeState(gs.state.PieceList))
+		if next < 0 || gs.distance[next] != distance-1 {
+			return false
+		}
+		fromPos := gs.pieceToPos(from[pieceIndex])
+		toPos := gs.pieceToPos(gs.state.PieceList[pieceIndex])
+		result.Step = Step{
+			PieceIndex: pieceIndex,
+			Direction:  []int16{toPos[0] - fromPos[0], toPos[1] - fromPos[1]},
+			Path:       gs.unitPath(pieceIndex, from[pieceIndex], gs.state.PieceList[pieceIndex]),
+		}
+		return true
+	})
+	return result, nil
+}
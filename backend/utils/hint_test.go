@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+// TestHint 沿提示一直走，每一步距离减一，最后获胜
+func TestHint(t *testing.T) {
+	game := classicGame()
+	gs := GameSolve{Options: SolveOptions{Metric: MetricPiece}}
+	gs.Init(game)
+	if _, err := gs.Analyze(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	player := replaySolve(game)
+	for distance := 81; distance > 0; distance-- {
+		hint, err := gs.Hint(player.Positions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hint.Solvable || hint.Distance != distance {
+			t.Fatalf("distance %d, solvable %v, want %d", hint.Distance, hint.Solvable, distance)
+		}
+		if !player.TryStep(hint.Step) {
+			t.Fatalf("hint step %+v cannot be played at distance %d", hint.Step, distance)
+		}
+	}
+	if !player.IsWin() {
+		t.Error("following the hints does not win")
+	}
+	hint, err := gs.Hint(player.Positions())
+	if err != nil || hint.Distance != 0 || hint.Step.PieceIndex != -1 {
+		t.Errorf("hint after winning: %+v %v", hint, err)
+	}
+}
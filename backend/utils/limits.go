@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 求解的资源限制，超出任何一项都返回 *LimitError，errors.Is(err, ErrLimitExceeded) 成立
+
+const (
+	LimitStates = "states" // 局面数
+	LimitTime   = "time"   // 用时
+	LimitMemory = "memory" // 内存
+)
+
+// LimitError 超出的限制
+type LimitError struct {
+	Limit string
+}
+
+func (e *LimitError) Error() string {
+	return "limit exceeded: " + e.Limit
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// LimitOf 错误对应的限制，不是因为超出限制而失败时返回空字符串
+func LimitOf(err error) string {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr.Limit
+	}
+	return ""
+}
+
+// startLimits 开始计时，每次搜索开始时调用
+func (gs *GameSolve) startLimits() {
+	gs.deadline = time.Time{}
+	if gs.Options.MaxTime > 0 {
+		gs.deadline = time.Now().Add(time.Duration(gs.Options.MaxTime) * time.Second)
+	}
+}
+
+// checkLimits 检查是否已取消或超出限制，states为已保存的局面数，为0时不检查局面数
+// 同时采样本次求解占用的内存，统计有一定开销，只在每展开一批局面时调用
+func (gs *GameSolve) checkLimits(ctx context.Context, states int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if states > gs.maxStates() {
+		return &LimitError{Limit: LimitStates}
+	}
+	if !gs.deadline.IsZero() && time.Now().After(gs.deadline) {
+		return &LimitError{Limit: LimitTime}
+	}
+	if used := gs.stats.observeMemory(gs.usedMemory()); gs.Options.MaxMemory > 0 && used > uint64(gs.Options.MaxMemory)<<20 {
+		return &LimitError{Limit: LimitMemory}
+	}
+	return nil
+}
+
+// usedMemory 本次求解的数据结构占用的字节数
+// 只计局面仓库、待展开队列等随局面数增长的部分，不受同时进行的其他求解和提示缓存的影响
+func (gs *GameSolve) usedMemory() uint64 {
+	used := gs.store.memory() + 4*uint64(cap(gs.distance))
+	if gs.searchMemory != nil {
+		used += gs.searchMemory()
+	}
+	return used
+}
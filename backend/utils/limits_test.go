@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestLimits 超出局面数、用时和内存时分别返回对应的限制
+func TestLimits(t *testing.T) {
+	for _, algorithm := range []Algorithm{AlgorithmBFS, AlgorithmAStar, AlgorithmBidirectional} {
+		game := classicGame()
+		gs := GameSolve{Options: SolveOptions{Metric: MetricPiece, Algorithm: algorithm, MaxStates: 500}}
+		gs.Init(game)
+		result, err := gs.Solve(context.Background())
+		if LimitOf(err) != LimitStates {
+			t.Errorf("%s: got %v", algorithm, err)
+		}
+		if result.Stats.PeakMemory == 0 {
+			t.Errorf("%s: no memory sampled", algorithm)
+		}
+	}
+
+	// IDA*只在开局仓库中保存开局，置换表也要计入内存
+	solved, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	layoutGame := classicGame()
+	layoutGame.Goal = &Goal{Layout: layoutAfter(t, layoutGame, solved.Steps, 12)}
+	idaStar := GameSolve{Options: SolveOptions{Metric: MetricPiece, Algorithm: AlgorithmIDAStar}}
+	idaStar.Init(layoutGame)
+	result, err := idaStar.Solve(context.Background())
+	if err != nil || result.Stats.PeakMemory <= idaStar.store.memory() {
+		t.Errorf("idastar: peak memory %d, store %d, %v", result.Stats.PeakMemory, idaStar.store.memory(), err)
+	}
+
+	gs := GameSolve{}
+	gs.Init(classicGame())
+	gs.startLimits()
+	gs.deadline = time.Now().Add(-time.Second)
+	if err := gs.checkLimits(context.Background(), 0); LimitOf(err) != LimitTime {
+		t.Errorf("deadline passed: %v", err)
+	}
+
+	gs.startLimits()
+	gs.Options.MaxMemory = 1
+	if err := gs.checkLimits(context.Background(), 0); err != nil {
+		t.Errorf("within memory limit: %v", err)
+	}
+	gs.searchMemory = func() uint64 {
+		return 2 << 20
+	}
+	if err := gs.checkLimits(context.Background(), 0); LimitOf(err) != LimitMemory {
+		t.Errorf("over memory limit: %v", err)
+	}
+}
+
+// TestMemoryLimitPerSolve 内存限制只计本次求解的数据结构，不受进程中其他内存的影响
+func TestMemoryLimitPerSolve(t *testing.T) {
+	other := make([]byte, 64<<20)
+	for _, algorithm := range []Algorithm{AlgorithmBFS, AlgorithmParallelBFS} {
+		gs := GameSolve{Options: SolveOptions{Metric: MetricPiece, Algorithm: algorithm, MaxMemory: 16}}
+		gs.Init(classicGame())
+		result, err := gs.Solve(context.Background())
+		if err != nil || result.Length != 81 {
+			t.Errorf("%s: length %d, %v", algorithm, result.Length, err)
+		}
+		if result.Stats.PeakMemory == 0 || result.Stats.PeakMemory > 16<<20 {
+			t.Errorf("%s: peak memory %d", algorithm, result.Stats.PeakMemory)
+		}
+	}
+	runtime.KeepAlive(other)
+}
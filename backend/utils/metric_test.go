@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+)
+
+// TestMetrics 三种计步方式在小棋盘上的最优步数
+// 按格计步每移动一格算一步；直线计步同一棋子沿同一方向连续移动算一步；按棋子计步同一棋子的连续移动算一步
+func TestMetrics(t *testing.T) {
+	straight := lineGame(4)
+	straight.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 3}}}}
+	turn := GameData{
+		BoardRows:      2,
+		BoardCols:      2,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 0}}},
+		Goal:           &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{1, 1}}}},
+	}
+	cases := []struct {
+		name   string
+		game   GameData
+		metric Metric
+		length int
+	}{
+		{"straight", straight, MetricStep, 3},
+		{"straight", straight, MetricStraight, 1},
+		{"straight", straight, MetricPiece, 1},
+		{"turn", turn, MetricStep, 2},
+		{"turn", turn, MetricStraight, 2},
+		{"turn", turn, MetricPiece, 1},
+		{"classic", classicGame(), MetricStep, 116},
+		{"classic", classicGame(), MetricStraight, 90},
+		{"classic", classicGame(), MetricPiece, 81},
+	}
+	for _, c := range cases {
+		result, err := solveGame(t, c.game, SolveOptions{Metric: c.metric})
+		if err != nil {
+			t.Errorf("%s, %s: %v", c.name, c.metric, err)
+			continue
+		}
+		if result.Length != c.length || result.Metric != c.metric || !result.Optimal {
+			t.Errorf("%s, %s: length %d, metric %s, optimal %v, want %d", c.name, c.metric, result.Length, result.Metric, result.Optimal, c.length)
+		}
+		verify, err := Verify(c.game, result.Steps)
+		if err != nil || !verify.Solved {
+			t.Errorf("%s, %s: solution does not verify", c.name, c.metric)
+		}
+		// 按棋子计步时解法的步数就是移动棋子的次数
+		if c.metric == MetricPiece && verify.Moves != c.length {
+			t.Errorf("%s: %d moves, want %d", c.name, verify.Moves, c.length)
+		}
+	}
+}
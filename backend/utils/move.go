@@ -0,0 +1,98 @@
+package utils
+
+// Metric 步数的计算方式
+type Metric string
+
+const (
+	MetricStep     Metric = "step"     // 棋子每移动一格算一步
+	MetricPiece    Metric = "piece"    // 同一棋子连续移动，不论路线和格数都算一步
+	MetricStraight Metric = "straight" // 同一棋子沿直线移动任意格算一步
+)
+
+// forEachMove 按计步方式枚举当前局面一步之内可到达的所有局面
+// 回调时 gs.state 已经是移动后的局面，回调返回true则停止枚举，此时局面保持移动后的状态
+func (gs *GameSolve) forEachMove(fn func(pieceIndex int16) bool) bool {
+	for pieceIndex := range gs.state.PieceList {
+		if gs.tryMove(int16(pieceIndex), fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// tryMove 枚举一枚棋子的所有走法，每走一步回调一次，回调之后把棋子移回原处
+func (gs *GameSolve) tryMove(pieceIndex int16, fn func(pieceIndex int16) bool) bool {
+	start := gs.state.PieceList[pieceIndex]
+	switch gs.metric {
+	case MetricStep:
+		for _, dir := range gs.dirs {
+			next := start + dir[0]*gs.boardCols + dir[1]
+			if !gs.canMove(pieceIndex, start, dir) {
+				continue
+			}
+			gs.movePiece(pieceIndex, next)
+			if fn(pieceIndex) {
+				return true
+			}
+			gs.movePiece(pieceIndex, start)
+		}
+	case MetricStraight:
+		for _, dir := range gs.dirs {
+			for piece := start; gs.canMove(pieceIndex, piece, dir); {
+				piece += dir[0]*gs.boardCols + dir[1]
+				gs.movePiece(pieceIndex, piece)
+				if fn(pieceIndex) {
+					return true
+				}
+			}
+			gs.movePiece(pieceIndex, start)
+		}
+	default:
+		// 从棋子原位置出发做一次洪泛，棋子能到达的每个位置都只算一步
+		gs.floodStamp++
+		gs.floodMark[start] = gs.floodStamp
+		queue := append(gs.floodQueue[:0], start)
+		for i := 0; i < len(queue); i++ {
+			for _, dir := range gs.dirs {
+				if !gs.canMove(pieceIndex, queue[i], dir) {
+					continue
+				}
+				next := queue[i] + dir[0]*gs.boardCols + dir[1]
+				if gs.floodMark[next] == gs.floodStamp {
+					continue
+				}
+				gs.floodMark[next] = gs.floodStamp
+				queue = append(queue, next)
+			}
+		}
+		gs.floodQueue = queue
+		for _, piece := range queue[1:] {
+			gs.movePiece(pieceIndex, piece)
+			if fn(pieceIndex) {
+				return true
+			}
+		}
+		gs.movePiece(pieceIndex, start)
+	}
+	return false
+}
+
+// canMove 位于piece处的棋子能否朝某个方向移动一格，棋盘上棋子自身占据的格子视为空
+func (gs *GameSolve) canMove(pieceIndex int16, piece int16, dir []int16) bool {
+	row0 := piece/gs.boardCols + dir[0]
+	col0 := piece%gs.boardCols + dir[1]
+	for _, cell := range gs.pieceKindShapeList[pieceIndex].Cells {
+		row := row0 + cell[0]
+		col := col0 + cell[1]
+		// 此格移动之后在棋盘上
+		if row < 0 || row >= gs.boardRows || col < 0 || col >= gs.boardCols {
+			return false
+		}
+		// 此格移动之后的位置是墙，或者已有棋子且棋子不是自身，则不能移动
+		grid := gs.state.Board[row*gs.boardCols+col]
+		if grid != 0 && grid != pieceIndex+1 {
+			return false
+		}
+	}
+	return true
+}
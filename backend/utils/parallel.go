@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	stateShardBits        = 6    // 局面集合分片数为 2^stateShardBits
+	parallelChunk         = 256  // 每个协程每次领取的局面数
+	parallelCheckInterval = 1024 // 每展开这么多局面检查一次是否取消
+)
+
+type stateShard struct {
+	sync.Mutex
+	store *stateStore
+}
+
+// shardedStateSet 分片加锁的局面集合，按哈希值的高位分片，多个协程可以同时写入不同的分片
+// 局面编号为 分片内索引*分片数+分片序号，父局面也用这个编号表示
+type shardedStateSet struct {
+	shards []stateShard
+	total  int64 // 所有分片中的局面数，原子更新，展开时随时可以读取
+}
+
+func newShardedStateSet(size int) *shardedStateSet {
+	set := &shardedStateSet{
+		shards: make([]stateShard, 1<<stateShardBits),
+	}
+	for i := range set.shards {
+		set.shards[i].store = newStateStore(size)
+	}
+	return set
+}
+
+func (s *shardedStateSet) add(state []byte, parent int32) (int32, bool) {
+	hash := hashState(state)
+	shardIndex := int32(hash >> (64 - stateShardBits))
+	shard := &s.shards[shardIndex]
+	shard.Lock()
+	index, isNew := shard.store.addHashed(state, hash, parent)
+	shard.Unlock()
+	if isNew {
+		atomic.AddInt64(&s.total, 1)
+	}
+	return index<<stateShardBits | shardIndex, isNew
+}
+
+// get 和 parent 只能在没有协程写入时调用
+func (s *shardedStateSet) get(id int32) []byte {
+	return s.shards[id&(1<<stateShardBits-1)].store.get(id >> stateShardBits)
+}
+
+func (s *shardedStateSet) parent(id int32) int32 {
+	return s.shards[id&(1<<stateShardBits-1)].store.parent[id>>stateShardBits]
+}
+
+// memory 所有分片占用的字节数
+func (s *shardedStateSet) memory() uint64 {
+	used := uint64(0)
+	for i := range s.shards {
+		s.shards[i].Lock()
+		used += s.shards[i].store.memory()
+		s.shards[i].Unlock()
+	}
+	return used
+}
+
+func (s *shardedStateSet) count() int {
+	return int(atomic.LoadInt64(&s.total))
+}
+
+// newWorker 复制一份求解器给协程使用，棋子形状等只读数据共用，计算用的缓冲各自独立
+func (gs *GameSolve) newWorker() *GameSolve {
+	worker := *gs
+	pieceCount := len(gs.startPieceList)
+	worker.state = GameState{
+		PieceList: make([]int16, pieceCount),
+		Board:     make([]int16, len(gs.state.Board)),
+	}
+	worker.stateBuf = make([]byte, len(gs.stateBuf))
+	worker.canonBuf = make([]int16, pieceCount)
+	worker.floodMark = make([]int32, len(gs.floodMark))
+	worker.floodStamp = 0
+	worker.floodQueue = nil
+	if len(gs.symmetries) > 0 {
+		worker.symBuf = make([]int16, pieceCount)
+		worker.symStateBuf = make([]byte, len(gs.symStateBuf))
+	}
+	worker.OnProgress = nil
+	return &worker
+}
+
+// parallelLevel 一层局面，编号和编码分开连续存放
+type parallelLevel struct {
+	ids  []int32
+	data []byte
+}
+
+// solveParallelBFS 逐层同步的并行广度优先搜索
+// 每层的局面分块交给多个协程展开，新局面写入分片的局面集合，一层全部展开后再进入下一层，
+// 因此找到的解法长度与单线程广度优先搜索相同
+func (gs *GameSolve) solveParallelBFS(ctx context.Context) (SolveResult, error) {
+	result := SolveResult{
+		Steps:     []Step{},
+		Metric:    gs.metric,
+		Algorithm: AlgorithmParallelBFS,
+		Optimal:   true,
+	}
+	gs.gameState2Board(gs.state)
+	if gs.isWin(gs.state) {
+		result.ForwardExplored = 1
+		return result, nil
+	}
+
+	workerCount := gs.Options.Workers
+	if workerCount <= 0 {
+		workerCount = runtime.NumCPU()
+	}
+	size := gs.store.size
+	set := newShardedStateSet(size)
+	startID, _ := set.add(gs.store.get(0), -1)
+	level := parallelLevel{
+		ids:  []int32{startID},
+		data: append([]byte{}, gs.store.get(0)...),
+	}
+	// 协程复制求解器时一并复制，按整个局面集合和正在展开的这一层计算内存
+	gs.searchMemory = func() uint64 {
+		return set.memory() + uint64(cap(level.data)+4*cap(level.ids))
+	}
+	defer func() {
+		gs.searchMemory = nil
+	}()
+	workers := make([]*GameSolve, workerCount)
+	for i := range workers {
+		workers[i] = gs.newWorker()
+	}
+
+	progress := newProgressReporter(gs.OnProgress)
+	explored := 0
+	maxStates := gs.maxStates()
+	for depth := 0; len(level.ids) > 0; depth++ {
+		if err := gs.checkLimits(ctx, set.count()); err != nil {
+			result.ForwardExplored = set.count()
+			return result, err
+		}
+		progress.report(explored, len(level.ids), depth)
+		gs.stats.observe(len(level.ids), depth)
+
+		var cursor int64
+		var stopped int32
+		winID := int32(-1)
+		var winOnce sync.Once
+		var stopErr error // 协程因取消或超出限制而停止的原因，只记录第一个
+		var stopOnce sync.Once
+		stop := func(err error) {
+			stopOnce.Do(func() {
+				stopErr = err
+			})
+			atomic.StoreInt32(&stopped, 1)
+		}
+		nextLevels := make([]parallelLevel, workerCount)
+		var wg sync.WaitGroup
+		for w, worker := range workers {
+			wg.Add(1)
+			go func(w int, worker *GameSolve) {
+				defer wg.Done()
+				next := &nextLevels[w]
+				expanded := 0
+				for atomic.LoadInt32(&stopped) == 0 {
+					start := int(atomic.AddInt64(&cursor, parallelChunk)) - parallelChunk
+					if start >= len(level.ids) {
+						return
+					}
+					end := start + parallelChunk
+					if end > len(level.ids) {
+						end = len(level.ids)
+					}
+					for i := start; i < end; i++ {
+						expanded++
+						if expanded%parallelCheckInterval == 0 {
+							if err := worker.checkLimits(ctx, set.count()); err != nil {
+								stop(err)
+								return
+							}
+						}
+						parent := level.ids[i]
+						worker.decodeState(level.data[i*size:(i+1)*size], worker.state.PieceList)
+						worker.gameState2Board(worker.state)
+						win := worker.forEachMove(func(pieceIndex int16) bool {
+							state := worker.encodeState(worker.state.PieceList)
+							id, isNew := set.add(state, parent)
+							worker.stats.addGenerated(isNew)
+							if !isNew {
+								return false
+							}
+							// 一层可能有大量局面，每保存一个新局面都检查局面数
+							if set.count() > maxStates {
+								stop(&LimitError{Limit: LimitStates})
+								return true
+							}
+							next.ids = append(next.ids, id)
+							next.data = append(next.data, state...)
+							if worker.isWin(worker.state) {
+								winOnce.Do(func() {
+									winID = id
+								})
+								return true
+							}
+							return false
+						})
+						if win {
+							// 找到解法或超出局面数，其他协程也停止
+							atomic.StoreInt32(&stopped, 1)
+							return
+						}
+					}
+				}
+			}(w, worker)
+		}
+		wg.Wait()
+		explored += len(level.ids)
+		for _, worker := range workers {
+			gs.stats.merge(worker.stats)
+			worker.stats = SolveStats{}
+		}
+
+		if winID >= 0 {
+			var states [][]byte
+			for id := winID; set.parent(id) >= 0; id = set.parent(id) {
+				states = append(states, set.get(id))
+			}
+			for i, j := 0, len(states)-1; i < j; i, j = i+1, j-1 {
+				states[i], states[j] = states[j], states[i]
+			}
+			result.Steps = gs.humanSteps(states)
+			result.Length = depth + 1
+			result.ForwardExplored = set.count()
+			return result, nil
+		}
+		if stopErr != nil {
+			result.ForwardExplored = set.count()
+			return result, stopErr
+		}
+
+		level = parallelLevel{}
+		for _, next := range nextLevels {
+			level.ids = append(level.ids, next.ids...)
+			level.data = append(level.data, next.data...)
+		}
+	}
+	result.ForwardExplored = set.count()
+	return result, errors.New("no solution")
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+// TestParallelBFS 并行搜索的解法长度与单线程广度优先搜索相同
+func TestParallelBFS(t *testing.T) {
+	cases := []struct {
+		metric Metric
+		length int
+	}{
+		{MetricStep, 116},
+		{MetricStraight, 90},
+		{MetricPiece, 81},
+	}
+	for _, c := range cases {
+		for _, workers := range []int{1, 4} {
+			gs := GameSolve{Options: SolveOptions{Metric: c.metric, Algorithm: AlgorithmParallelBFS, Workers: workers}}
+			gs.Init(classicGame())
+			result, err := gs.Solve(context.Background())
+			if err != nil || result.Length != c.length {
+				t.Errorf("%s, %d workers: length %d, %v", c.metric, workers, result.Length, err)
+				continue
+			}
+			verify, err := Verify(classicGame(), result.Steps)
+			if err != nil || !verify.Solved {
+				t.Errorf("%s, %d workers: solution does not verify", c.metric, workers)
+			}
+		}
+	}
+}
+
+// TestParallelMaxStates 局面数在一层之内超过上限时也及时停止
+func TestParallelMaxStates(t *testing.T) {
+	const maxStates = 1000
+	workers := 4
+	gs := GameSolve{Options: SolveOptions{Metric: MetricPiece, Algorithm: AlgorithmParallelBFS, Workers: workers, MaxStates: maxStates}}
+	gs.Init(classicGame())
+	result, err := gs.Solve(context.Background())
+	if LimitOf(err) != LimitStates {
+		t.Fatalf("got %v", err)
+	}
+	// 每个协程最多多保存正在展开的那个局面的一批后继
+	if result.ForwardExplored > maxStates+workers*64 {
+		t.Errorf("explored %d states with a limit of %d", result.ForwardExplored, maxStates)
+	}
+}
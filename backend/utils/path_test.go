@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+// checkPaths 每一步的路线由单格移动组成，合计等于 Direction
+func checkPaths(t *testing.T, name string, steps []Step) {
+	t.Helper()
+	for i, step := range steps {
+		sum := []int16{0, 0}
+		for _, dir := range step.Path {
+			if len(dir) != 2 || abs16(dir[0])+abs16(dir[1]) != 1 {
+				t.Errorf("%s: step %d has a path entry %v", name, i, dir)
+			}
+			sum[0] += dir[0]
+			sum[1] += dir[1]
+		}
+		if len(step.Path) == 0 || !reflect.DeepEqual(sum, step.Direction) {
+			t.Errorf("%s: step %d path %v does not add up to %v", name, i, step.Path, step.Direction)
+		}
+	}
+}
+
+// TestSolutionPaths 解法的每一步都带有逐格的路线，对称规约和同类棋子互换之后路线仍然可以重放
+func TestSolutionPaths(t *testing.T) {
+	for _, metric := range []Metric{MetricStep, MetricStraight, MetricPiece} {
+		result, err := solveGame(t, classicGame(), SolveOptions{Metric: metric})
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkPaths(t, string(metric), result.Steps)
+		verify, err := Verify(classicGame(), result.Steps)
+		if err != nil || !verify.Solved {
+			t.Errorf("%s: solution does not verify with paths", metric)
+		}
+	}
+}
+
+// TestTurningPath 绕过墙的移动记录拐弯的路线，按路线直走穿墙或终点不一致时不能走
+func TestTurningPath(t *testing.T) {
+	game := GameData{
+		BoardRows:      2,
+		BoardCols:      3,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		Walls:          []Pos{{0, 1}},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 0}}},
+		Goal:           &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 2}}}},
+	}
+	result, err := solveGame(t, game, SolveOptions{Metric: MetricPiece})
+	if err != nil || len(result.Steps) != 1 {
+		t.Fatalf("steps %+v, %v", result.Steps, err)
+	}
+	want := [][]int16{{1, 0}, {0, 1}, {0, 1}, {-1, 0}}
+	if step := result.Steps[0]; !reflect.DeepEqual(step.Path, want) || !reflect.DeepEqual(step.Direction, []int16{0, 2}) {
+		t.Errorf("step %+v", step)
+	}
+
+	// 只给 Direction 时任何能走到的路线都可以
+	if verify, _ := Verify(game, []Step{{PieceIndex: 0, Direction: []int16{0, 2}}}); !verify.Solved {
+		t.Error("move without a path was rejected")
+	}
+	// 路线的终点与 Direction 不一致
+	short := []Step{{PieceIndex: 0, Direction: []int16{0, 2}, Path: [][]int16{{1, 0}, {0, 1}}}}
+	if verify, _ := Verify(game, short); verify.Valid {
+		t.Error("path ending elsewhere was accepted")
+	}
+	// 路线中有斜向的移动
+	diagonal := []Step{{PieceIndex: 0, Direction: []int16{0, 2}, Path: [][]int16{{1, 1}, {-1, 1}}}}
+	if verify, _ := Verify(game, diagonal); verify.Valid {
+		t.Error("diagonal path was accepted")
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+// 供试玩使用的规则，与求解时的走法和胜利判断完全相同
+
+// SetPositions 把当前局面设为给定的棋子位置，棋子超出棋盘、压在墙上或相互重叠时返回false
+func (gs *GameSolve) SetPositions(positions []Pos) bool {
+	if len(positions) != len(gs.state.PieceList) {
+		return false
+	}
+	copy(gs.state.Board, gs.wallBoard)
+	for pieceIndex, pos := range positions {
+		if len(pos) != 2 {
+			return false
+		}
+		for _, cell := range gs.pieceKindShapeList[pieceIndex].Cells {
+			row, col := pos[0]+cell[0], pos[1]+cell[1]
+			if row < 0 || row >= gs.boardRows || col < 0 || col >= gs.boardCols {
+				return false
+			}
+			grid := row*gs.boardCols + col
+			if gs.state.Board[grid] != 0 {
+				return false
+			}
+			gs.state.Board[grid] = int16(pieceIndex) + 1
+		}
+		gs.state.PieceList[pieceIndex] = gs.posToPiece(pos)
+	}
+	return true
+}
+
+// Positions 当前局面每个棋子的位置
+func (gs *GameSolve) Positions() []Pos {
+	positions := make([]Pos, len(gs.state.PieceList))
+	for i, piece := range gs.state.PieceList {
+		positions[i] = gs.pieceToPos(piece)
+	}
+	return positions
+}
+
+// TryStep 按计步方式走一步，目标位置必须是这枚棋子一步之内能到达的位置
+// 带有路线时路线上的每一格也必须能走，且路线的终点与 Direction 一致
+// 可以走时局面更新为走完之后的局面并返回true，否则局面不变
+func (gs *GameSolve) TryStep(step Step) bool {
+	if step.PieceIndex < 0 || int(step.PieceIndex) >= len(gs.state.PieceList) || len(step.Direction) != 2 {
+		return false
+	}
+	if step.Direction[0] == 0 && step.Direction[1] == 0 {
+		return false
+	}
+	pos := gs.pieceToPos(gs.state.PieceList[step.PieceIndex])
+	row, col := pos[0]+step.Direction[0], pos[1]+step.Direction[1]
+	if row < 0 || row >= gs.boardRows || col < 0 || col >= gs.boardCols {
+		return false
+	}
+	target := row*gs.boardCols + col
+	if len(step.Path) > 0 && !gs.canFollow(step.PieceIndex, step.Path, target) {
+		return false
+	}
+	return gs.tryMove(step.PieceIndex, func(pieceIndex int16) bool {
+		return gs.state.PieceList[pieceIndex] == target
+	})
+}
+
+// canFollow 棋子能否在其他棋子不动时沿路线逐格走到target
+func (gs *GameSolve) canFollow(pieceIndex int16, path [][]int16, target int16) bool {
+	piece := gs.state.PieceList[pieceIndex]
+	for _, dir := range path {
+		if len(dir) != 2 || abs16(dir[0])+abs16(dir[1]) != 1 || !gs.canMove(pieceIndex, piece, dir) {
+			return false
+		}
+		piece += dir[0]*gs.boardCols + dir[1]
+	}
+	return piece == target
+}
+
+// IsWin 当前局面是否已经获胜
+func (gs *GameSolve) IsWin() bool {
+	return gs.isWin(gs.state)
+}
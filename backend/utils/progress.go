@@ -0,0 +1,42 @@
+package utils
+
+import "time"
+
+// SolveProgress 求解进度
+type SolveProgress struct {
+	Explored  int   `json:"explored"`  // 已展开的局面数
+	QueueSize int   `json:"queueSize"` // 待计算的局面数
+	Depth     int   `json:"depth"`     // 当前搜索深度
+	Elapsed   int64 `json:"elapsed"`   // 已用时间，毫秒
+}
+
+const progressInterval = 200 * time.Millisecond // 进度回调的最小间隔
+
+// progressReporter 按间隔回调进度
+type progressReporter struct {
+	onProgress       func(progress SolveProgress)
+	startTime        time.Time
+	lastProgressTime time.Time
+}
+
+func newProgressReporter(onProgress func(progress SolveProgress)) *progressReporter {
+	now := time.Now()
+	return &progressReporter{
+		onProgress:       onProgress,
+		startTime:        now,
+		lastProgressTime: now,
+	}
+}
+
+func (p *progressReporter) report(explored int, queueSize int, depth int) {
+	if p.onProgress == nil || time.Since(p.lastProgressTime) < progressInterval {
+		return
+	}
+	p.lastProgressTime = time.Now()
+	p.onProgress(SolveProgress{
+		Explored:  explored,
+		QueueSize: queueSize,
+		Depth:     depth,
+		Elapsed:   time.Since(p.startTime).Milliseconds(),
+	})
+}
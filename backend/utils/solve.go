@@ -1,15 +1,13 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
-var baseDirs [][]int16 // 基础方向，每个棋子的可移动方向
-var flipDir []int16
-
-type Board = [][]int16
 type Shape = [][]bool
 type Pos = []int16
 
@@ -32,179 +30,304 @@ type GameData struct {
 	KingPieceIndex int16   `json:"kingPieceIndex"`
 	KingWinPos     Pos     `json:"kingWinPos"`
 	Door           Door    `json:"door"`
+	Goal           *Goal   `json:"goal,omitempty"`  // 获胜条件，为空时只要求王棋到达出口
+	Walls          []Pos   `json:"walls,omitempty"` // 棋盘内不能放置棋子的格子，用于棋盘内的障碍和不规则的棋盘轮廓
 }
 
+// GameState 展开后的局面，仅在计算当前局面时使用
 type GameState struct {
-	PieceList    []int16 `json:"pieceList"`
-	Board        Board
-	PreGameState *GameState
+	PieceList []int16 `json:"pieceList"` // 每个棋子左上角所在格子的序号
+	Board     []int16 // 棋盘，按行展开，每格为 棋子索引+1，0为空，wallGrid为墙
 }
 
 type Step struct {
-	PieceIndex int16   `json:"pieceIndex"`
-	Direction  []int16 `json:"direction"`
+	PieceIndex int16     `json:"pieceIndex"`
+	Direction  []int16   `json:"direction"` // 移动前后位置之差
+	Path       [][]int16 `json:"path"`      // 逐格的移动方向，依次走完即到达目标位置，为空时只看 Direction
 }
 
 type PieceKindShape struct {
 	Kind  uint8
 	Shape Shape
+	Cells []Pos // 棋子占据的格子相对左上角的偏移
+}
+
+// Algorithm 搜索算法
+type Algorithm string
+
+const (
+	AlgorithmBFS           Algorithm = "bfs"           // 从开局出发的广度优先搜索
+	AlgorithmBidirectional Algorithm = "bidirectional" // 双向广度优先搜索，需要完整的目标布局且没有其他目标，否则退回单向搜索
+	AlgorithmAStar         Algorithm = "astar"         // A*搜索
+	AlgorithmIDAStar       Algorithm = "idastar"       // 迭代加深A*搜索，内存占用受限
+	AlgorithmParallelBFS   Algorithm = "parallel"      // 多核并行的逐层广度优先搜索
+	AlgorithmExternal      Algorithm = "external"      // 把局面存在磁盘上的逐层广度优先搜索，可以从检查点继续
+)
+
+// ErrLimitExceeded 搜索超出了限制
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+const defaultMaxStates = 20000000 // 默认最多保存的局面数
+
+// SolverVersion 求解器的版本，求解结果可能改变时加一，缓存的旧版本解法不再使用
+const SolverVersion = 1
+
+const wallGrid int16 = -1 // 棋盘上墙所在的格子
+
+// SolveOptions 求解选项
+type SolveOptions struct {
+	Metric       Metric    `json:"metric"`       // 步数的计算方式，默认按棋子计步
+	Algorithm    Algorithm `json:"algorithm"`    // 搜索算法，默认广度优先
+	Heuristic    string    `json:"heuristic"`    // A*和IDA*使用的启发函数，默认goal
+	MaxStates    int       `json:"maxStates"`    // 最多保存的局面数，IDA*为置换表的容量，外存搜索不限制
+	MaxTime      int       `json:"maxTime"`      // 最长用时，秒，为0时不限制
+	MaxMemory    int       `json:"maxMemory"`    // 本次求解的数据结构占用内存的大致上限，MB，为0时不限制
+	Workers      int       `json:"workers"`      // 并行搜索的协程数，默认为CPU核数
+	NoSymmetry   bool      `json:"noSymmetry"`   // 不按对称规约局面
+	ScratchDir   string    `json:"scratchDir"`   // 外存搜索存放临时文件和检查点的目录，默认为系统临时目录
+	MemoryBudget int       `json:"memoryBudget"` // 外存搜索缓存后继局面的内存上限，MB，默认256
+}
+
+// SolveResult 求解结果
+type SolveResult struct {
+	Steps            []Step     `json:"steps"`
+	Metric           Metric     `json:"metric"`
+	Algorithm        Algorithm  `json:"algorithm"`        // 实际使用的搜索算法
+	Length           int        `json:"length"`           // 按所选计步方式计算的解法长度
+	Optimal          bool       `json:"optimal"`          // 是否已证明为最优解
+	ForwardExplored  int        `json:"forwardExplored"`  // 从开局出发访问的局面数
+	BackwardExplored int        `json:"backwardExplored"` // 从目标布局出发访问的局面数
+	Stats            SolveStats `json:"stats"`            // 求解的统计，失败时为已经得到的部分
 }
 
 type GameSolve struct {
+	dirs               [][]int16 // 基础方向，每个棋子的可移动方向；求解器不使用包级的可变状态，可以同时运行多个
 	boardRows          int16
 	boardCols          int16
 	kingIndex          int16
 	kingWinPos         Pos
 	pieceKindShapeList []PieceKindShape
-	gameStateStrSet    map[string]bool // 局面字符串集合，将局面转字符串（用棋子排序保证唯一），存入集合，{局面字符串}
-	gameStateList      []GameState     // 局面列表，存储所有待计算的局面
+	kindGroups         [][]int16    // 按类型分组的棋子索引，同组棋子可以互换
+	posBytes           int          // 每个棋子位置编码占用的字节数
+	startPieceList     []int16      // 开局时每个棋子的位置
+	goal               *Goal        // 获胜条件，为nil时按王棋到达出口判断
+	targetState        []byte       // 目标布局的编码，没有目标布局时为nil
+	targetPieceList    []int16      // 规范化之后的目标布局
+	pieceGoals         []pieceGoal  // 有单独目标的棋子
+	regionGoals        []regionGoal // 区域目标
+	kingWinCells       []int16      // 王棋在出口位置时占据的格子
+	wallBoard          []int16      // 只有墙的棋盘，重建棋盘时以它为底；墙是固定的，不计入局面编码
+	symmetries         []symmetry   // 布局的对称变换，不含恒等变换
+	symBuf             []int16      // 对称变换缓冲
+	symStateBuf        []byte       // 对称局面编码缓冲
+	distance           []int32      // 分析之后，仓库中每个局面到目标的距离，无法到达为-1
+	store              *stateStore  // 局面仓库，按广度优先的顺序存放所有局面，兼作待计算队列
+	state              GameState    // 正在展开的局面
+	stateBuf           []byte       // 局面编码缓冲
+	canonBuf           []int16      // 局面规范化缓冲
+	floodMark          []int32      // 按棋子计步时，标记棋子已到达过的位置
+	floodStamp         int32
+	floodQueue         []int16
 	doorPlacement      string
-	Times              uint64
+	metric             Metric
+	deadline           time.Time     // 超过这一时间后停止搜索，为零值时不限制
+	searchMemory       func() uint64 // 搜索算法自己的数据结构占用的字节数，由算法在搜索期间设置
+	stats              SolveStats    // 本次求解的统计
+	Options            SolveOptions
+	OnProgress         func(progress SolveProgress) // 进度回调，可为空
 }
 
-//var tryCount int
-
 func (gs *GameSolve) Init(game GameData) {
-	baseDirs = [][]int16{
+	gs.dirs = [][]int16{
 		{1, 0},
 		{0, 1},
 		{-1, 0},
 		{0, -1},
 	}
-	flipDir = []int16{
-		2, 3, 0, 1,
-	}
-
 	gs.boardRows = game.BoardRows
 	gs.boardCols = game.BoardCols
 	gs.kingIndex = game.KingPieceIndex
 	gs.kingWinPos = game.KingWinPos
-	gs.gameStateStrSet = map[string]bool{}
 	gs.doorPlacement = game.Door.Placement
+	gs.metric = gs.Options.Metric
+	if gs.metric == "" {
+		gs.metric = MetricPiece
+	}
+	gs.posBytes = 1
+	if int(gs.boardRows)*int(gs.boardCols) > 256 {
+		gs.posBytes = 2
+	}
 	pieceKindStrMap := make(map[string]uint8)
-	startGameState := GameState{
-		PieceList: make([]int16, 0),
-	} // 开局局面，存储棋子类型和位置，方便后续甄别是否重复局面，[棋子索引:棋子类型+位置]
+	kindGroupMap := make(map[uint8]int)
 	kindCount := uint8(0)
+	goalPieces := goalPieceSet(game)
 	// 完善棋子类型列表、棋子形状与类型的映射、开局局面
 	for i, piece := range game.PieceList {
 		shapeStr := shape2Str(piece.Shape)
 		kind, isContains := pieceKindStrMap[shapeStr]
-		if !isContains || int16(i) == game.KingPieceIndex {
+		separate := int16(i) == game.KingPieceIndex || goalPieces[int16(i)]
+		if !isContains || separate {
 			kindCount++
 			kind = kindCount
-			pieceKindStrMap[shapeStr] = kind
+			// 王棋和有单独目标的棋子独占一个类型，不与同形状的棋子互换
+			if !separate {
+				pieceKindStrMap[shapeStr] = kind
+			}
 		}
-		startGameState.PieceList = append(startGameState.PieceList, gs.posToPiece(piece.Position))
+		gs.startPieceList = append(gs.startPieceList, gs.posToPiece(piece.Position))
 
+		var cells []Pos
+		for rowIndex, row := range piece.Shape {
+			for colIndex, grid := range row {
+				if grid {
+					cells = append(cells, Pos{int16(rowIndex), int16(colIndex)})
+				}
+			}
+		}
 		gs.pieceKindShapeList = append(gs.pieceKindShapeList, PieceKindShape{
 			Kind:  kind,
 			Shape: piece.Shape,
+			Cells: cells,
 		})
 
+		groupIndex, isContains := kindGroupMap[kind]
+		if !isContains {
+			groupIndex = len(gs.kindGroups)
+			kindGroupMap[kind] = groupIndex
+			gs.kindGroups = append(gs.kindGroups, []int16{})
+		}
+		gs.kindGroups[groupIndex] = append(gs.kindGroups[groupIndex], int16(i))
 	}
 
-	board, boardStr := gs.gameState2Board(startGameState)
-	startGameState.Board = board
-	gs.gameStateStrSet[boardStr] = true // 将开始局面存入局面字符串集合
-	gs.gameStateList = append(gs.gameStateList, startGameState)
-}
-
-func (gs *GameSolve) Solve() ([]Step, error) {
-	startGameState := gs.gameStateList[0]
-	// 判断开始局面是否已经赢了
-	if gs.isWin(startGameState) {
-		return []Step{}, nil
+	pieceCount := len(gs.startPieceList)
+	gs.stateBuf = make([]byte, pieceCount*gs.posBytes)
+	gs.canonBuf = make([]int16, pieceCount)
+	gs.floodMark = make([]int32, int(gs.boardRows)*int(gs.boardCols))
+	gs.state = GameState{
+		PieceList: make([]int16, pieceCount),
+		Board:     make([]int16, int(gs.boardRows)*int(gs.boardCols)),
 	}
-
-	for len(gs.gameStateList) > 0 {
-
-		gameState := gs.gameStateList[0]
-		gs.gameStateList = gs.gameStateList[1:]
-		for pieceIndex := 0; pieceIndex < len(gameState.PieceList); pieceIndex++ {
-			win, steps := gs.tryMove(gameState, int16(pieceIndex), map[int16]bool{})
-			if win {
-				//println(tryCount)
-				return steps, nil
-			}
+	gs.wallBoard = make([]int16, len(gs.state.Board))
+	for _, wall := range game.Walls {
+		gs.wallBoard[gs.posToPiece(wall)] = wallGrid
+	}
+	copy(gs.state.PieceList, gs.startPieceList)
+	gs.store = newStateStore(len(gs.stateBuf))
+	gs.store.add(gs.encodeState(gs.startPieceList), -1) // 将开始局面存入局面仓库
+	gs.initGoal(game)
+	if gs.goal == nil && gs.kingIndex >= 0 && int(gs.kingIndex) < pieceCount && len(gs.kingWinPos) == 2 {
+		for _, cell := range gs.pieceKindShapeList[gs.kingIndex].Cells {
+			gs.kingWinCells = append(gs.kingWinCells, (gs.kingWinPos[0]+cell[0])*gs.boardCols+gs.kingWinPos[1]+cell[1])
 		}
 	}
-	return []Step{}, errors.New("no solution")
+	gs.gameState2Board(gs.state)
+	gs.initSymmetry(game)
 }
 
-func (gs *GameSolve) tryMove(gameState GameState, pieceIndex int16, banDirsSet map[int16]bool) (bool, []Step) {
-	//printBoard(gameState.Board)
-
-	piece := gameState.PieceList[pieceIndex]
-	pos := gs.pieceToPos(piece)
-	pieceShape := gs.pieceKindShapeList[pieceIndex].Shape
-	for dirIndex, dir := range baseDirs {
-		if _, isContains := banDirsSet[int16(dirIndex)]; isContains {
-			continue
-		}
-		for rowIndex, row := range pieceShape {
-			for colIndex := range row {
-				// 如果棋子此格本身为空，此格移动不存在覆盖其他棋子的情况，跳过
-				if gameState.Board[pos[0]+int16(rowIndex)][pos[1]+int16(colIndex)] == 0 {
-					continue
-				}
-				// 此格移动之后在棋盘上
-				inBoard := pos[0]+dir[0]+int16(rowIndex) >= 0 &&
-					pos[0]+dir[0]+int16(rowIndex) < gs.boardRows &&
-					pos[1]+dir[1]+int16(colIndex) >= 0 &&
-					pos[1]+dir[1]+int16(colIndex) < gs.boardCols
-				if !inBoard {
-					goto NextDir
-				}
-				gridBeforePieceIndex := gameState.Board[pos[0]+dir[0]+int16(rowIndex)][pos[1]+dir[1]+int16(colIndex)]
+// Solve 按选项中的算法求解，返回所选计步方式下的最优解
+// 超出限制时返回 *LimitError，结果中仍带有已经访问的局面数等统计
+func (gs *GameSolve) Solve(ctx context.Context) (SolveResult, error) {
+	gs.startLimits()
+	gs.stats = SolveStats{}
+	start := time.Now()
+	result, err := gs.solve(ctx)
+	gs.stats.observeMemory(gs.usedMemory())
+	gs.stats.observe(0, result.Length)
+	gs.stats.Elapsed = time.Since(start).Milliseconds()
+	result.Stats = gs.stats
+	return result, err
+}
 
-				// 此格移动之后的位置，棋盘已有棋子，且棋子不是自身，则不能移动
-				if gridBeforePieceIndex > 0 && gridBeforePieceIndex != int16(pieceIndex)+1 {
-					goto NextDir
-				}
-			}
+func (gs *GameSolve) solve(ctx context.Context) (SolveResult, error) {
+	switch gs.Options.Algorithm {
+	case AlgorithmBidirectional:
+		// 反向搜索只从目标布局出发，另有棋子目标或区域目标时相遇的路线不一定满足它们
+		if gs.targetState != nil && len(gs.pieceGoals) == 0 && len(gs.regionGoals) == 0 {
+			return gs.solveBidirectional(ctx)
 		}
-		{
-
-			// 可以移动
-			newGameState := cloneGameState(gameState)
-			newGameState.PieceList[pieceIndex] = gs.posToPiece([]int16{
-				pos[0] + dir[0],
-				pos[1] + dir[1],
-			})
-			board, boardStr := gs.gameState2Board(newGameState)
+	case AlgorithmAStar:
+		return gs.solveAStar(ctx)
+	case AlgorithmIDAStar:
+		return gs.solveIDAStar(ctx)
+	case AlgorithmParallelBFS:
+		return gs.solveParallelBFS(ctx)
+	case AlgorithmExternal:
+		return gs.solveExternal(ctx)
+	}
+	return gs.solveBFS(ctx)
+}
 
-			newGameState.Board = board
-			if _, isContains := gs.gameStateStrSet[boardStr]; isContains {
-				// 已经存在该局面
-				continue
-			}
+// solveBFS 广度优先搜索，逐层展开
+func (gs *GameSolve) solveBFS(ctx context.Context) (SolveResult, error) {
+	result := SolveResult{
+		Steps:     []Step{},
+		Metric:    gs.metric,
+		Algorithm: AlgorithmBFS,
+		Optimal:   true,
+	}
+	gs.gameState2Board(gs.state)
+	// 判断开始局面是否已经赢了
+	if gs.isWin(gs.state) {
+		result.ForwardExplored = gs.store.count
+		return result, nil
+	}
 
-			//tryCount++
-			gs.gameStateStrSet[boardStr] = true
-			win := gs.isWin(newGameState)
-			if win {
-				return true, gs.humanSteps(newGameState)
+	progress := newProgressReporter(gs.OnProgress)
+	for head := int32(0); int(head) < gs.store.count; head++ {
+		// 每展开一批局面检查一次是否取消或超出限制，并按间隔回调进度
+		if head%1024 == 0 {
+			// 先记录深度，超出限制时统计中也有已经搜索到的深度
+			depth := gs.store.depth(head)
+			gs.stats.observe(0, depth)
+			if err := gs.checkLimits(ctx, gs.store.count); err != nil {
+				result.ForwardExplored = gs.store.count
+				return result, err
 			}
+			progress.report(int(head), gs.store.count-int(head), depth)
+		}
 
-			// 看看这枚棋子是否能够继续移动
-			newWin, steps := gs.tryMove(newGameState, pieceIndex, map[int16]bool{
-				flipDir[dirIndex]: true,
-			})
-			gs.gameStateList = append(gs.gameStateList, newGameState)
-			if newWin {
-				return true, steps
+		gs.decodeState(gs.store.get(head), gs.state.PieceList)
+		gs.gameState2Board(gs.state)
+		winIndex := int32(-1)
+		gs.forEachMove(func(pieceIndex int16) bool {
+			index, isNew := gs.store.add(gs.encodeState(gs.state.PieceList), head)
+			gs.stats.addGenerated(isNew)
+			if isNew && gs.isWin(gs.state) {
+				winIndex = index
+				return true
 			}
-
+			return false
+		})
+		gs.stats.observe(gs.store.count-int(head)-1, 0)
+		if winIndex >= 0 {
+			result.Steps = gs.humanSteps(gs.store.path(winIndex))
+			result.Length = gs.store.depth(winIndex)
+			result.ForwardExplored = gs.store.count
+			return result, nil
 		}
-	NextDir:
-		continue
 	}
-	// 如果没有可移动的方向，则返回
-	return false, []Step{}
+	result.ForwardExplored = gs.store.count
+	return result, errors.New("no solution")
+}
+
+// movePiece 在当前局面上把棋子移到新位置，同步更新棋盘
+func (gs *GameSolve) movePiece(pieceIndex int16, piece int16) {
+	cells := gs.pieceKindShapeList[pieceIndex].Cells
+	pos := gs.pieceToPos(gs.state.PieceList[pieceIndex])
+	for _, cell := range cells {
+		gs.state.Board[(pos[0]+cell[0])*gs.boardCols+pos[1]+cell[1]] = 0
+	}
+	pos = gs.pieceToPos(piece)
+	for _, cell := range cells {
+		gs.state.Board[(pos[0]+cell[0])*gs.boardCols+pos[1]+cell[1]] = pieceIndex + 1
+	}
+	gs.state.PieceList[pieceIndex] = piece
 }
 
 func (gs *GameSolve) isWin(gameState GameState) bool {
+	if gs.goal != nil {
+		return gs.isGoal(gameState)
+	}
 	kingPos := gs.pieceToPos(gameState.PieceList[gs.kingIndex])
 	if kingPos[0] != gs.kingWinPos[0] || kingPos[1] != gs.kingWinPos[1] {
 		return false
@@ -215,7 +338,7 @@ func (gs *GameSolve) isWin(gameState GameState) bool {
 		for colIndex, grid := range row {
 			// 假如棋子上此格为空，棋盘上此格不为空，说明是别的棋子
 			// 判断此格是否阻挡王棋进入门
-			if !grid && gameState.Board[int(kingPos[0])+rowIndex][int(kingPos[1])+colIndex] != 0 {
+			if !grid && gameState.Board[(int(kingPos[0])+rowIndex)*int(gs.boardCols)+int(kingPos[1])+colIndex] != 0 {
 				switch gs.doorPlacement {
 				case "bottom":
 					for rowI := rowIndex; rowI >= 0; rowI-- {
@@ -225,14 +348,14 @@ func (gs *GameSolve) isWin(gameState GameState) bool {
 					}
 					break
 				case "top":
-					for rowI := rowIndex; rowI <= len(kingPieceShape); rowI++ {
+					for rowI := rowIndex; rowI < len(kingPieceShape); rowI++ {
 						if kingPieceShape[rowI][colIndex] {
 							return false
 						}
 					}
 					break
 				case "left":
-					for colI := colIndex; colI <= len(kingPieceShape[0]); colI++ {
+					for colI := colIndex; colI < len(kingPieceShape[0]); colI++ {
 						if kingPieceShape[rowIndex][colI] {
 							return false
 						}
@@ -252,34 +375,60 @@ func (gs *GameSolve) isWin(gameState GameState) bool {
 	return true
 }
 
-func (gs *GameSolve) gameState2Board(gameState GameState) (Board, string) {
-	board := make(Board, gs.boardRows)
-	kindList := make([]uint8, gs.boardRows*gs.boardCols) // 准备一个uint8数组表示棋盘的每一格的棋子类型, []unit8可以直转string
-
-	for i := int16(0); i < gs.boardRows; i++ {
-		board[i] = make([]int16, gs.boardCols)
-	}
+// gameState2Board 根据棋子位置和墙重建棋盘
+func (gs *GameSolve) gameState2Board(gameState GameState) {
+	copy(gameState.Board, gs.wallBoard)
 	for pieceIndex, piece := range gameState.PieceList {
 		pos := gs.pieceToPos(piece)
-		pieceKindShape := gs.pieceKindShapeList[pieceIndex]
-		for rowIndex, row := range pieceKindShape.Shape {
-			for colIndex, grid := range row {
-				if grid {
-					board[pos[0]+int16(rowIndex)][pos[1]+int16(colIndex)] = int16(pieceIndex) + 1
-					kindList[(rowIndex+int(pos[0]))*int(gs.boardCols)+colIndex+int(pos[1])] = pieceKindShape.Kind + 33 // kind字符从33开始
-				}
+		for _, cell := range gs.pieceKindShapeList[pieceIndex].Cells {
+			gameState.Board[(pos[0]+cell[0])*gs.boardCols+pos[1]+cell[1]] = int16(pieceIndex) + 1
+		}
+	}
+}
+
+// canonicalize 同类型的棋子可以互换，把每组棋子的位置从小到大排列，保证同一局面只有一种表示
+func (gs *GameSolve) canonicalize(pieceList []int16) {
+	for _, group := range gs.kindGroups {
+		for i := 1; i < len(group); i++ {
+			for j := i; j > 0 && pieceList[group[j]] < pieceList[group[j-1]]; j-- {
+				pieceList[group[j]], pieceList[group[j-1]] = pieceList[group[j-1]], pieceList[group[j]]
 			}
 		}
 	}
-	for i, v := range kindList {
-		if v == 0 {
-			kindList[i] = 32
+}
+
+// encodeState 把棋子位置规范化后编码成定长字节，布局对称时取对称局面中最小的编码
+// 返回的切片在下次编码前有效
+func (gs *GameSolve) encodeState(pieceList []int16) []byte {
+	gs.encodeCanonical(pieceList, gs.stateBuf)
+	if len(gs.symmetries) > 0 {
+		gs.reduceSymmetry(pieceList)
+	}
+	return gs.stateBuf
+}
+
+// encodeCanonical 把棋子位置规范化后编码写入buf，不做对称规约
+func (gs *GameSolve) encodeCanonical(pieceList []int16, buf []byte) {
+	copy(gs.canonBuf, pieceList)
+	gs.canonicalize(gs.canonBuf)
+	for i, piece := range gs.canonBuf {
+		if gs.posBytes == 1 {
+			buf[i] = byte(piece)
+		} else {
+			buf[i*2] = byte(piece >> 8)
+			buf[i*2+1] = byte(piece)
 		}
 	}
-	//fmt.Printf("kindList: %+v\n", kindList)
-	boardStr := string(kindList)
-	return board, boardStr
+}
 
+func (gs *GameSolve) decodeState(state []byte, pieceList []int16) {
+	for i := range pieceList {
+		if gs.posBytes == 1 {
+			pieceList[i] = int16(state[i])
+		} else {
+			pieceList[i] = int16(state[i*2])<<8 | int16(state[i*2+1])
+		}
+	}
 }
 
 /**
@@ -299,73 +448,129 @@ func shape2Str(shape Shape) string {
 	return strings.Join(strArr, "")
 }
 
-func (gs *GameSolve) humanSteps(gameState GameState) []Step {
+// humanSteps 从开局依次重放规范化局面，还原每个棋子的真实移动
+// 仓库中的局面是规范化的，同类棋子的索引可能互换，需要按位置找到真正移动的棋子；
+// 按对称规约过的局面先还原为原方向上的局面。
+// 同一棋子的连续移动合并为一步，并记录逐格的路线，拐弯的移动也能按原路重放
+func (gs *GameSolve) humanSteps(states [][]byte) []Step {
+	states = gs.unfoldStates(states)
+	// 在单独的局面上重放，不影响当前局面
+	saved := gs.state
+	defer func() {
+		gs.state = saved
+	}()
+	gs.state = GameState{
+		PieceList: append([]int16{}, gs.startPieceList...),
+		Board:     make([]int16, len(gs.wallBoard)),
+	}
+	gs.gameState2Board(gs.state)
+	pieceList := gs.state.PieceList
+	current := make([]int16, len(pieceList))
+	next := make([]int16, len(pieceList))
 	var res []Step
-	for gameState.PreGameState != nil {
-		//fmt.Println("=============")
-		//for _, row := range gameState.Board {
-		//	fmt.Printf("%+v\n", row)
-		//}
-		for pieceIndex, piece := range gameState.PieceList {
-			if piece != gameState.PreGameState.PieceList[pieceIndex] {
-				diff := piece - gameState.PreGameState.PieceList[pieceIndex]
-				dir := make([]int16, 0)
-				if diff == 1 || diff == -1 {
-					dir = []int16{0, diff}
-				} else if diff == gs.boardCols {
-					dir = []int16{1, 0}
-				} else if diff == -gs.boardCols {
-					dir = []int16{-1, 0}
-				}
-				if len(res) > 0 && int16(pieceIndex) == res[len(res)-1].PieceIndex {
-					res[len(res)-1].Direction = []int16{
-						res[len(res)-1].Direction[0] + dir[0],
-						res[len(res)-1].Direction[1] + dir[1],
-					}
-				} else {
-					res = append(res, Step{
-						PieceIndex: int16(pieceIndex),
-						Direction:  dir,
-					})
-				}
+	for _, state := range states {
+		copy(current, pieceList)
+		gs.canonicalize(current)
+		gs.decodeState(state, next)
+		pieceIndex, from, to := gs.diffState(pieceList, current, next)
+		if pieceIndex < 0 {
+			continue
+		}
+		path := gs.unitPath(pieceIndex, from, to)
+		gs.movePiece(pieceIndex, to)
+		fromPos := gs.pieceToPos(from)
+		toPos := gs.pieceToPos(to)
+		dir := []int16{toPos[0] - fromPos[0], toPos[1] - fromPos[1]}
+		if len(res) > 0 && pieceIndex == res[len(res)-1].PieceIndex {
+			last := &res[len(res)-1]
+			last.Direction = []int16{
+				last.Direction[0] + dir[0],
+				last.Direction[1] + dir[1],
 			}
+			last.Path = append(last.Path, path...)
+		} else {
+			res = append(res, Step{
+				PieceIndex: pieceIndex,
+				Direction:  dir,
+				Path:       path,
+			})
 		}
-		gameState = *gameState.PreGameState
 	}
-	var temp Step
-	length := len(res)
-	for i := 0; i < length/2; i++ {
-		temp = res[i]
-		res[i] = res[length-1-i]
-		res[length-1-i] = temp
-	}
-	//fmt.Printf("%+v", res)
 	return res
 }
 
-func (gs *GameSolve) pieceToPos(piece int16) []int16 {
-	return []int16{
-		piece / gs.boardCols,
-		piece % gs.boardCols,
+// unitPath 其他棋子不动时，棋子从from逐格走到to的最短路线，每项为一格的移动方向
+func (gs *GameSolve) unitPath(pieceIndex int16, from int16, to int16) [][]int16 {
+	prev := map[int16]int16{from: -1}
+	queue := []int16{from}
+	for i := 0; i < len(queue) && queue[i] != to; i++ {
+		for _, dir := range gs.dirs {
+			if !gs.canMove(pieceIndex, queue[i], dir) {
+				continue
+			}
+			next := queue[i] + dir[0]*gs.boardCols + dir[1]
+			if _, ok := prev[next]; ok {
+				continue
+			}
+			prev[next] = queue[i]
+			queue = append(queue, next)
+		}
+	}
+	if _, ok := prev[to]; !ok {
+		return nil
+	}
+	var path [][]int16
+	for piece := to; piece != from; piece = prev[piece] {
+		pos := gs.pieceToPos(piece)
+		prevPos := gs.pieceToPos(prev[piece])
+		path = append(path, []int16{pos[0] - prevPos[0], pos[1] - prevPos[1]})
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
 	}
+	return path
 }
 
-func (gs *GameSolve) posToPiece(pos []int16) int16 {
-	return pos[0]*gs.boardCols + pos[1]
+// diffState 比较两个规范化局面，找出移动的棋子（真实索引）及其移动前后的位置
+func (gs *GameSolve) diffState(pieceList []int16, current []int16, next []int16) (int16, int16, int16) {
+	for _, group := range gs.kindGroups {
+		from, to := int16(-1), int16(-1)
+		for _, i := range group {
+			if !groupContains(next, group, current[i]) {
+				from = current[i]
+			}
+			if !groupContains(current, group, next[i]) {
+				to = next[i]
+			}
+		}
+		if from < 0 {
+			continue
+		}
+		for _, i := range group {
+			if pieceList[i] == from {
+				return i, from, to
+			}
+		}
+	}
+	return -1, -1, -1
 }
 
-func cloneGameState(state GameState) GameState {
-	gameState := GameState{
-		PieceList:    make([]int16, len(state.PieceList)),
-		PreGameState: &state,
+func groupContains(pieceList []int16, group []int16, piece int16) bool {
+	for _, i := range group {
+		if pieceList[i] == piece {
+			return true
+		}
 	}
-	copy(gameState.PieceList, state.PieceList)
-	return gameState
+	return false
 }
 
-func printBoard(board Board) {
-	fmt.Println("=============")
-	for _, row := range board {
-		fmt.Printf("%+v\n", row)
+func (gs *GameSolve) pieceToPos(piece int16) []int16 {
+	return []int16{
+		piece / gs.boardCols,
+		piece % gs.boardCols,
 	}
 }
+
+func (gs *GameSolve) posToPiece(pos []int16) int16 {
+	return pos[0]*gs.boardCols + pos[1]
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// classicGame 横刀立马
+func classicGame() GameData {
+	v := Shape{{true}, {true}}
+	h := Shape{{true, true}}
+	s := Shape{{true}}
+	k := Shape{{true, true}, {true, true}}
+	return GameData{
+		BoardRows:      5,
+		BoardCols:      4,
+		KingPieceIndex: 0,
+		KingWinPos:     Pos{3, 1},
+		Door:           Door{Placement: "bottom", StartIndex: 1, XSize: 2, YSize: 2},
+		PieceList: []Piece{
+			{k, Pos{0, 1}}, {v, Pos{0, 0}}, {v, Pos{0, 3}}, {v, Pos{2, 0}}, {v, Pos{2, 3}},
+			{h, Pos{2, 1}}, {s, Pos{3, 1}}, {s, Pos{3, 2}}, {s, Pos{4, 0}}, {s, Pos{4, 3}},
+		},
+	}
+}
+
+// TestConcurrentSolve 同时运行多个求解，用 go test -race 检查求解器之间没有共享的可变状态
+func TestConcurrentSolve(t *testing.T) {
+	cases := []struct {
+		options SolveOptions
+		length  int
+	}{
+		{SolveOptions{Metric: MetricStep}, 116},
+		{SolveOptions{Metric: MetricStraight}, 90},
+		{SolveOptions{Metric: MetricPiece}, 81},
+		{SolveOptions{Metric: MetricPiece, NoSymmetry: true}, 81},
+		{SolveOptions{Metric: MetricStep, Algorithm: AlgorithmAStar}, 116},
+		{SolveOptions{Metric: MetricPiece, Algorithm: AlgorithmParallelBFS, Workers: 2}, 81},
+	}
+	var wg sync.WaitGroup
+	for _, c := range cases {
+		wg.Add(1)
+		go func(options SolveOptions, length int) {
+			defer wg.Done()
+			gs := GameSolve{Options: options}
+			gs.Init(classicGame())
+			result, err := gs.Solve(context.Background())
+			if err != nil {
+				t.Errorf("%v: %v", options, err)
+				return
+			}
+			if result.Length != length {
+				t.Errorf("%v: length %d, want %d", options, result.Length, length)
+			}
+			verify, err := Verify(classicGame(), result.Steps)
+			if err != nil || !verify.Solved {
+				t.Errorf("%v: solution does not verify: %+v %v", options, verify, err)
+			}
+		}(c.options, c.length)
+	}
+	wg.Wait()
+}
+
+// TestConcurrentAnalyze 同时分析和求解同一布局
+func TestConcurrentAnalyze(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gs := GameSolve{Options: SolveOptions{Metric: MetricPiece}}
+			gs.Init(classicGame())
+			analysis, err := gs.Analyze(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if analysis.Reachable != 25955 || analysis.StartDistance != 81 {
+				t.Errorf("reachable %d, start distance %d", analysis.Reachable, analysis.StartDistance)
+			}
+		}()
+	}
+	wg.Wait()
+}
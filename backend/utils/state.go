@@ -0,0 +1,116 @@
+package utils
+
+// stateStore 局面仓库
+// 所有局面按加入顺序紧凑地存放在一块连续内存中，父局面用索引表示，
+// 去重使用开放寻址的哈希表，表中存放局面索引，不再单独保存局面字符串
+type stateStore struct {
+	size   int     // 每个局面占用的字节数
+	data   []byte  // 局面数据，第i个局面为 data[i*size:(i+1)*size]
+	parent []int32 // 父局面索引，开局为-1
+	table  []int32 // 哈希表，存放 局面索引+1，0表示空位
+	count  int     // 局面数量
+}
+
+const stateStoreInitTableSize = 1 << 10
+
+func newStateStore(size int) *stateStore {
+	return &stateStore{
+		size:  size,
+		table: make([]int32, stateStoreInitTableSize),
+	}
+}
+
+func (s *stateStore) get(index int32) []byte {
+	start := int(index) * s.size
+	return s.data[start : start+s.size]
+}
+
+// find 查找局面的索引，不存在返回-1
+func (s *stateStore) find(state []byte) int32 {
+	mask := uint64(len(s.table) - 1)
+	for i := hashState(state) & mask; ; i = (i + 1) & mask {
+		slot := s.table[i]
+		if slot == 0 {
+			return -1
+		}
+		if string(s.get(slot-1)) == string(state) {
+			return slot - 1
+		}
+	}
+}
+
+// add 加入局面，返回局面索引以及是否为新局面
+func (s *stateStore) add(state []byte, parent int32) (int32, bool) {
+	return s.addHashed(state, hashState(state), parent)
+}
+
+// addHashed 同add，哈希值由调用方算好
+func (s *stateStore) addHashed(state []byte, hash uint64, parent int32) (int32, bool) {
+	if (s.count+1)*2 > len(s.table) {
+		s.grow()
+	}
+	mask := uint64(len(s.table) - 1)
+	i := hash & mask
+	for ; s.table[i] != 0; i = (i + 1) & mask {
+		if string(s.get(s.table[i]-1)) == string(state) {
+			return s.table[i] - 1, false
+		}
+	}
+	index := int32(s.count)
+	s.data = append(s.data, state...)
+	s.parent = append(s.parent, parent)
+	s.table[i] = index + 1
+	s.count++
+	return index, true
+}
+
+// grow 哈希表扩容一倍并重新放置所有局面
+func (s *stateStore) grow() {
+	table := make([]int32, len(s.table)*2)
+	mask := uint64(len(table) - 1)
+	for index := 0; index < s.count; index++ {
+		i := hashState(s.get(int32(index))) & mask
+		for table[i] != 0 {
+			i = (i + 1) & mask
+		}
+		table[i] = int32(index) + 1
+	}
+	s.table = table
+}
+
+// memory 仓库占用的字节数
+func (s *stateStore) memory() uint64 {
+	return uint64(cap(s.data)) + 4*uint64(cap(s.parent)+cap(s.table))
+}
+
+// depth 局面到开局的步数
+func (s *stateStore) depth(index int32) int {
+	depth := 0
+	for s.parent[index] >= 0 {
+		index = s.parent[index]
+		depth++
+	}
+	return depth
+}
+
+// path 从开局到该局面经过的所有局面，不含开局
+func (s *stateStore) path(index int32) [][]byte {
+	var states [][]byte
+	for ; s.parent[index] >= 0; index = s.parent[index] {
+		states = append(states, s.get(index))
+	}
+	for i, j := 0, len(states)-1; i < j; i, j = i+1, j-1 {
+		states[i], states[j] = states[j], states[i]
+	}
+	return states
+}
+
+// hashState FNV-1a
+func hashState(state []byte) uint64 {
+	hash := uint64(14695981039346656037)
+	for _, b := range state {
+		hash ^= uint64(b)
+		hash *= 1099511628211
+	}
+	return hash
+}
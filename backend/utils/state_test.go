@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"testing"
+)
+
+// TestEncodeState 编码定长，同类型棋子互换位置编码相同，解码得到规范化的位置
+func TestEncodeState(t *testing.T) {
+	gs := GameSolve{Options: SolveOptions{NoSymmetry: true}}
+	gs.Init(classicGame())
+	pieceList := append([]int16{}, gs.startPieceList...)
+	state := append([]byte{}, gs.encodeState(pieceList)...)
+	if len(state) != len(pieceList) {
+		t.Fatalf("state length %d, want %d", len(state), len(pieceList))
+	}
+	decoded := make([]int16, len(pieceList))
+	gs.decodeState(state, decoded)
+	if string(gs.encodeState(decoded)) != string(state) {
+		t.Error("decoded state encodes differently")
+	}
+
+	// 交换两枚竖放的棋子和两枚单格棋子
+	pieceList[1], pieceList[2] = pieceList[2], pieceList[1]
+	pieceList[6], pieceList[9] = pieceList[9], pieceList[6]
+	if string(gs.encodeState(pieceList)) != string(state) {
+		t.Error("swapping identical pieces changed the encoding")
+	}
+	// 王棋与其他棋子不能互换
+	pieceList[0], pieceList[6] = pieceList[6], pieceList[0]
+	if string(gs.encodeState(pieceList)) == string(state) {
+		t.Error("swapping the king with another piece kept the encoding")
+	}
+}
+
+// TestEncodeLargeBoard 超过256格的棋盘每个位置用两个字节
+func TestEncodeLargeBoard(t *testing.T) {
+	game := GameData{
+		BoardRows:      20,
+		BoardCols:      20,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 0}}, {Shape{{true}}, Pos{19, 19}}},
+		Goal:           &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 1}}}},
+	}
+	gs := GameSolve{}
+	gs.Init(game)
+	state := gs.encodeState(gs.startPieceList)
+	if len(state) != 4 {
+		t.Fatalf("state length %d, want 4", len(state))
+	}
+	decoded := make([]int16, 2)
+	gs.decodeState(state, decoded)
+	if decoded[0] != 0 || decoded[1] != 399 {
+		t.Errorf("decoded %v", decoded)
+	}
+}
+
+// TestStateStore 重复的局面不再加入，扩容之后仍能找到所有局面和它们的路线
+func TestStateStore(t *testing.T) {
+	store := newStateStore(2)
+	count := stateStoreInitTableSize * 4
+	key := func(i int) []byte {
+		return []byte{byte(i >> 8), byte(i)}
+	}
+	for i := 0; i < count; i++ {
+		parent := int32(i - 1)
+		index, isNew := store.add(key(i), parent)
+		if !isNew || index != int32(i) {
+			t.Fatalf("state %d: index %d, new %v", i, index, isNew)
+		}
+	}
+	if index, isNew := store.add(key(5), 100); isNew || index != 5 || store.parent[5] != 4 {
+		t.Errorf("duplicate state: index %d, new %v, parent %d", index, isNew, store.parent[5])
+	}
+	if store.count != count || store.find(key(count)) != -1 {
+		t.Errorf("count %d", store.count)
+	}
+	for i := 0; i < count; i += 97 {
+		if index := store.find(key(i)); index != int32(i) {
+			t.Errorf("find %d: got %d", i, index)
+		}
+	}
+	if depth := store.depth(10); depth != 10 {
+		t.Errorf("depth %d, want 10", depth)
+	}
+	path := store.path(3)
+	if len(path) != 3 || path[0][1] != 1 || path[2][1] != 3 {
+		t.Errorf("path %v", path)
+	}
+}
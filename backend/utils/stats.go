@@ -0,0 +1,46 @@
+package utils
+
+// SolveStats 一次求解的统计，用于比较求解器的不同版本和布局的难度
+type SolveStats struct {
+	Generated    int    `json:"generated"`    // 生成的后继局面数，含重复的局面
+	Duplicates   int    `json:"duplicates"`   // 其中已经访问过而被丢弃的局面数
+	PeakFrontier int    `json:"peakFrontier"` // 待展开局面数的峰值
+	MaxDepth     int    `json:"maxDepth"`     // 搜索到的最大深度
+	Elapsed      int64  `json:"elapsed"`      // 用时，毫秒
+	PeakMemory   uint64 `json:"peakMemory"`   // 本次求解的数据结构占用内存的峰值，字节；按间隔采样，是近似值
+}
+
+// addGenerated 记录生成了一个后继局面，isNew为false时表示它已经访问过
+func (s *SolveStats) addGenerated(isNew bool) {
+	s.Generated++
+	if !isNew {
+		s.Duplicates++
+	}
+}
+
+// observe 记录当前待展开的局面数和搜索深度
+func (s *SolveStats) observe(frontier int, depth int) {
+	if frontier > s.PeakFrontier {
+		s.PeakFrontier = frontier
+	}
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+}
+
+// observeMemory 记录内存采样，返回采样值
+func (s *SolveStats) observeMemory(used uint64) uint64 {
+	if used > s.PeakMemory {
+		s.PeakMemory = used
+	}
+	return used
+}
+
+// merge 合并并行协程各自的统计
+func (s *SolveStats) merge(other SolveStats) {
+	s.Generated += other.Generated
+	s.Duplicates += other.Duplicates
+	if other.PeakMemory > s.PeakMemory {
+		s.PeakMemory = other.PeakMemory
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+// TestSolveStats 统计与访问的局面数一致，失败时也带有已经得到的统计
+func TestSolveStats(t *testing.T) {
+	for _, algorithm := range []Algorithm{AlgorithmBFS, AlgorithmParallelBFS} {
+		result, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece, Algorithm: algorithm, Workers: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats := result.Stats
+		// 除开局以外每个访问过的局面都是作为新的后继生成的
+		if stats.Generated-stats.Duplicates != result.ForwardExplored-1 {
+			t.Errorf("%s: generated %d, duplicates %d, explored %d", algorithm, stats.Generated, stats.Duplicates, result.ForwardExplored)
+		}
+		if stats.MaxDepth != result.Length || stats.PeakFrontier == 0 || stats.PeakMemory == 0 || stats.Elapsed < 0 {
+			t.Errorf("%s: %+v", algorithm, stats)
+		}
+	}
+
+	gs := GameSolve{Options: SolveOptions{Metric: MetricPiece, MaxStates: 1000}}
+	gs.Init(classicGame())
+	result, err := gs.Solve(context.Background())
+	if LimitOf(err) != LimitStates || result.Stats.Generated == 0 || result.Stats.MaxDepth == 0 {
+		t.Errorf("limited solve: %+v, %v", result.Stats, err)
+	}
+}
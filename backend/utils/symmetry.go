@@ -0,0 +1,198 @@
+package utils
+
+import "bytes"
+
+// symmetry 棋盘的一种对称变换，左右翻转、上下翻转或者两者同时
+// 变换后棋子的形状可能变成另一种类型，slot 记录每个棋子变换后放到哪个棋子的位置
+type symmetry struct {
+	flipRows bool
+	flipCols bool
+	slot     []int16
+}
+
+// initSymmetry 找出棋盘、出口、墙、获胜条件以及所有棋子形状都不变的对称变换
+// 局面编码时取所有对称局面中最小的一个，对称的局面只保存一次
+func (gs *GameSolve) initSymmetry(game GameData) {
+	if gs.Options.NoSymmetry {
+		return
+	}
+	separate := goalPieceSet(game)
+	separate[game.KingPieceIndex] = true
+	for _, flip := range [][]bool{{false, true}, {true, false}, {true, true}} {
+		sym := symmetry{
+			flipRows: flip[0],
+			flipCols: flip[1],
+		}
+		if gs.symmetryValid(game, &sym, separate) {
+			gs.symmetries = append(gs.symmetries, sym)
+		}
+	}
+	if len(gs.symmetries) > 0 {
+		pieceCount := len(gs.startPieceList)
+		gs.symBuf = make([]int16, pieceCount)
+		gs.symStateBuf = make([]byte, len(gs.stateBuf))
+		// 开局已经按不规约的编码存入仓库，重新存放
+		gs.store = newStateStore(len(gs.stateBuf))
+		gs.store.add(gs.encodeState(gs.startPieceList), -1)
+	}
+}
+
+// symmetryValid 检查变换是否保持布局不变，同时计算棋子的对应关系
+func (gs *GameSolve) symmetryValid(game GameData, sym *symmetry, separate map[int16]bool) bool {
+	for _, wall := range game.Walls {
+		if gs.wallBoard[gs.flipPiece(sym, gs.posToPiece(wall), 1, 1)] != wallGrid {
+			return false
+		}
+	}
+
+	// 每组棋子变换后的形状必须是另一组数量相同的棋子，王棋和有单独目标的棋子只能对应自身
+	sym.slot = make([]int16, len(gs.startPieceList))
+	for _, group := range gs.kindGroups {
+		flipped := shape2Str(flipShape(gs.pieceKindShapeList[group[0]].Shape, sym))
+		var target []int16
+		if separate[group[0]] {
+			if shape2Str(gs.pieceKindShapeList[group[0]].Shape) == flipped {
+				target = group
+			}
+		} else {
+			for _, other := range gs.kindGroups {
+				if !separate[other[0]] && len(other) == len(group) && shape2Str(gs.pieceKindShapeList[other[0]].Shape) == flipped {
+					target = other
+					break
+				}
+			}
+		}
+		if target == nil {
+			return false
+		}
+		for i, pieceIndex := range group {
+			sym.slot[pieceIndex] = target[i]
+		}
+	}
+
+	if gs.goal == nil {
+		// 王棋出口位置不变，且出口不在翻转的方向上
+		if sym.flipCols && (gs.doorPlacement == "left" || gs.doorPlacement == "right") ||
+			sym.flipRows && (gs.doorPlacement == "top" || gs.doorPlacement == "bottom") {
+			return false
+		}
+		kingWinPiece := gs.posToPiece(gs.kingWinPos)
+		return gs.flipPiece(sym, kingWinPiece, gs.pieceRows(gs.kingIndex), gs.pieceCols(gs.kingIndex)) == kingWinPiece
+	}
+	for _, goal := range gs.pieceGoals {
+		if gs.flipPiece(sym, goal.piece, gs.pieceRows(goal.pieceIndex), gs.pieceCols(goal.pieceIndex)) != goal.piece {
+			return false
+		}
+	}
+	if gs.targetState != nil {
+		gs.flipPieceList(sym, gs.targetPieceList, gs.canonBuf)
+		flipped := append([]int16{}, gs.canonBuf...)
+		gs.canonicalize(flipped)
+		for i := range flipped {
+			if flipped[i] != gs.targetPieceList[i] {
+				return false
+			}
+		}
+	}
+	for i, region := range gs.regionGoals {
+		if shape2Str(flipShape(game.Goal.Regions[i].Shape, sym)) != shape2Str(game.Goal.Regions[i].Shape) {
+			return false
+		}
+		for cell, inside := range region.mask {
+			if inside && !region.mask[gs.flipPiece(sym, int16(cell), 1, 1)] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// flipPiece 变换左上角位于piece处、占据rows行cols列的棋子，返回变换后左上角的位置
+func (gs *GameSolve) flipPiece(sym *symmetry, piece int16, rows int16, cols int16) int16 {
+	row, col := piece/gs.boardCols, piece%gs.boardCols
+	if sym.flipRows {
+		row = gs.boardRows - rows - row
+	}
+	if sym.flipCols {
+		col = gs.boardCols - cols - col
+	}
+	return row*gs.boardCols + col
+}
+
+// flipPieceList 变换整个局面，结果写入to，未规范化
+func (gs *GameSolve) flipPieceList(sym *symmetry, pieceList []int16, to []int16) {
+	for i, piece := range pieceList {
+		to[sym.slot[i]] = gs.flipPiece(sym, piece, gs.pieceRows(int16(i)), gs.pieceCols(int16(i)))
+	}
+}
+
+func (gs *GameSolve) pieceRows(pieceIndex int16) int16 {
+	return int16(len(gs.pieceKindShapeList[pieceIndex].Shape))
+}
+
+func (gs *GameSolve) pieceCols(pieceIndex int16) int16 {
+	return int16(len(gs.pieceKindShapeList[pieceIndex].Shape[0]))
+}
+
+func flipShape(shape Shape, sym *symmetry) Shape {
+	rows, cols := len(shape), len(shape[0])
+	flipped := make(Shape, rows)
+	for r := range flipped {
+		flipped[r] = make([]bool, cols)
+		for c := range flipped[r] {
+			fromRow, fromCol := r, c
+			if sym.flipRows {
+				fromRow = rows - 1 - r
+			}
+			if sym.flipCols {
+				fromCol = cols - 1 - c
+			}
+			flipped[r][c] = shape[fromRow][fromCol]
+		}
+	}
+	return flipped
+}
+
+// reduceSymmetry 把stateBuf中的编码换成所有对称局面中最小的编码
+func (gs *GameSolve) reduceSymmetry(pieceList []int16) {
+	for i := range gs.symmetries {
+		gs.flipPieceList(&gs.symmetries[i], pieceList, gs.symBuf)
+		gs.encodeCanonical(gs.symBuf, gs.symStateBuf)
+		if bytes.Compare(gs.symStateBuf, gs.stateBuf) < 0 {
+			copy(gs.stateBuf, gs.symStateBuf)
+		}
+	}
+}
+
+// disableSymmetry 关闭对称规约，需要区分对称局面时（如统计局面数）使用，只能在搜索开始之前调用
+func (gs *GameSolve) disableSymmetry() {
+	if len(gs.symmetries) == 0 {
+		return
+	}
+	gs.symmetries = nil
+	gs.store = newStateStore(len(gs.stateBuf))
+	gs.store.add(gs.encodeState(gs.startPieceList), -1)
+}
+
+// unfoldStates 把按对称规约的局面序列还原为从开局出发、原方向上真实经过的局面
+// 每一步在当前真实局面的所有后继中，找出规约后与序列中下一个局面相同的一个
+func (gs *GameSolve) unfoldStates(states [][]byte) [][]byte {
+	if len(gs.symmetries) == 0 {
+		return states
+	}
+	copy(gs.state.PieceList, gs.startPieceList)
+	gs.gameState2Board(gs.state)
+	unfolded := make([][]byte, 0, len(states))
+	for _, state := range states {
+		found := gs.forEachMove(func(pieceIndex int16) bool {
+			return string(gs.encodeState(gs.state.PieceList)) == string(state)
+		})
+		if !found {
+			break
+		}
+		plain := make([]byte, len(gs.stateBuf))
+		gs.encodeCanonical(gs.state.PieceList, plain)
+		unfolded = append(unfolded, plain)
+	}
+	return unfolded
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"testing"
+)
+
+// TestSymmetry 左右对称的经典布局按对称规约后访问的局面更少，解法长度不变且仍然可以重放
+func TestSymmetry(t *testing.T) {
+	gs := GameSolve{}
+	gs.Init(classicGame())
+	if len(gs.symmetries) != 1 || gs.symmetries[0].flipRows || !gs.symmetries[0].flipCols {
+		t.Fatalf("symmetries %+v", gs.symmetries)
+	}
+	for _, metric := range []Metric{MetricStep, MetricStraight, MetricPiece} {
+		reduced, err := solveGame(t, classicGame(), SolveOptions{Metric: metric})
+		if err != nil {
+			t.Fatal(err)
+		}
+		full, err := solveGame(t, classicGame(), SolveOptions{Metric: metric, NoSymmetry: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reduced.Length != full.Length || reduced.ForwardExplored >= full.ForwardExplored {
+			t.Errorf("%s: length %d/%d, explored %d/%d", metric, reduced.Length, full.Length, reduced.ForwardExplored, full.ForwardExplored)
+		}
+		verify, err := Verify(classicGame(), reduced.Steps)
+		if err != nil || !verify.Solved {
+			t.Errorf("%s: reduced solution does not verify", metric)
+		}
+	}
+}
+
+// TestSymmetryBroken 不对称的墙或目标使对称变换失效
+func TestSymmetryBroken(t *testing.T) {
+	// 3行3列，棋子进入最下面一行即可，左右对称
+	game := GameData{
+		BoardRows:      3,
+		BoardCols:      3,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 0}}, {Shape{{true, true}}, Pos{1, 1}}},
+		Goal:           &Goal{Regions: []RegionGoal{{Shape: Shape{{true}}, Cells: []Pos{{2, 0}, {2, 1}, {2, 2}}}}},
+	}
+	count := func(game GameData) int {
+		gs := GameSolve{}
+		gs.Init(game)
+		return len(gs.symmetries)
+	}
+	if n := count(game); n != 1 {
+		t.Errorf("%d symmetries, want 1", n)
+	}
+	walled := game
+	walled.Walls = []Pos{{2, 0}}
+	if n := count(walled); n != 0 {
+		t.Errorf("asymmetric wall: %d symmetries", n)
+	}
+	pieceGoal := game
+	pieceGoal.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{2, 0}}}}
+	if n := count(pieceGoal); n != 0 {
+		t.Errorf("asymmetric goal: %d symmetries", n)
+	}
+
+	for i, g := range []GameData{game, walled, pieceGoal} {
+		reduced, err := solveGame(t, g, SolveOptions{Metric: MetricStep})
+		if err != nil {
+			t.Fatal(err)
+		}
+		full, err := solveGame(t, g, SolveOptions{Metric: MetricStep, NoSymmetry: true})
+		if err != nil || reduced.Length != full.Length {
+			t.Errorf("case %d: length %d/%d, %v", i, reduced.Length, full.Length, err)
+		}
+	}
+}
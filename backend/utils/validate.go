@@ -0,0 +1,177 @@
+package utils
+
+// ValidationError 布局中的一处错误
+type ValidationError struct {
+	Code       string `json:"code"`       // 错误类型，同时是前端的翻译键
+	PieceIndex int    `json:"pieceIndex"` // 出错的棋子索引，与棋子无关时为-1
+}
+
+const maxBoardGrids = 1 << 15 // 格子序号用int16表示
+
+// Validate 检查布局的结构是否完整，返回所有发现的错误，没有错误时返回空列表
+// 求解器假定布局合法，求解前必须先检查，否则越界会导致程序崩溃
+func Validate(game GameData) []ValidationError {
+	errs := []ValidationError{}
+	if game.BoardRows <= 0 || game.BoardCols <= 0 || int(game.BoardRows)*int(game.BoardCols) > maxBoardGrids {
+		return append(errs, ValidationError{Code: "invalidBoardSize", PieceIndex: -1})
+	}
+	if len(game.PieceList) == 0 {
+		return append(errs, ValidationError{Code: "noPieces", PieceIndex: -1})
+	}
+
+	walls := make([]bool, int(game.BoardRows)*int(game.BoardCols))
+	for _, wall := range game.Walls {
+		if len(wall) != 2 || wall[0] < 0 || wall[1] < 0 || wall[0] >= game.BoardRows || wall[1] >= game.BoardCols {
+			return append(errs, ValidationError{Code: "invalidWall", PieceIndex: -1})
+		}
+		walls[wall[0]*game.BoardCols+wall[1]] = true
+	}
+
+	// 逐个棋子检查形状、位置，并在棋盘上检查重叠
+	board := make([]bool, len(walls))
+	validShapes := make([]bool, len(game.PieceList))
+	for i, piece := range game.PieceList {
+		if !validShape(piece.Shape) {
+			errs = append(errs, ValidationError{Code: "invalidPieceShape", PieceIndex: i})
+			continue
+		}
+		validShapes[i] = true
+		if len(piece.Position) != 2 || !shapeInBoard(game, piece.Shape, piece.Position) {
+			errs = append(errs, ValidationError{Code: "pieceOutOfBoard", PieceIndex: i})
+			continue
+		}
+		if onWall(walls, game.BoardCols, piece.Shape, piece.Position) {
+			errs = append(errs, ValidationError{Code: "pieceOnWall", PieceIndex: i})
+			continue
+		}
+		if !markShape(board, game.BoardCols, piece.Shape, piece.Position) {
+			errs = append(errs, ValidationError{Code: "pieceOverlap", PieceIndex: i})
+		}
+	}
+
+	if game.Goal.isEmpty() {
+		kingIndex := int(game.KingPieceIndex)
+		if kingIndex < 0 || kingIndex >= len(game.PieceList) {
+			errs = append(errs, ValidationError{Code: "kingIndexOutOfRange", PieceIndex: -1})
+		} else if validShapes[kingIndex] && (len(game.KingWinPos) != 2 || !shapeInBoard(game, game.PieceList[kingIndex].Shape, game.KingWinPos) ||
+			onWall(walls, game.BoardCols, game.PieceList[kingIndex].Shape, game.KingWinPos)) {
+			errs = append(errs, ValidationError{Code: "invalidKingWinPos", PieceIndex: kingIndex})
+		}
+		return errs
+	}
+	return append(errs, validateGoal(game, validShapes, walls)...)
+}
+
+// validateGoal 检查获胜条件中的每个目标
+func validateGoal(game GameData, validShapes []bool, walls []bool) []ValidationError {
+	errs := []ValidationError{}
+	goal := game.Goal
+	// 有王棋时王棋仍需存在，没有王棋时为-1
+	if game.KingPieceIndex < -1 || int(game.KingPieceIndex) >= len(game.PieceList) {
+		errs = append(errs, ValidationError{Code: "kingIndexOutOfRange", PieceIndex: -1})
+	}
+
+	for _, pieceGoal := range goal.Pieces {
+		i := int(pieceGoal.PieceIndex)
+		if i < 0 || i >= len(game.PieceList) {
+			errs = append(errs, ValidationError{Code: "invalidGoalPiece", PieceIndex: -1})
+			continue
+		}
+		shape := game.PieceList[i].Shape
+		if validShapes[i] && (len(pieceGoal.Position) != 2 || !shapeInBoard(game, shape, pieceGoal.Position) ||
+			onWall(walls, game.BoardCols, shape, pieceGoal.Position)) {
+			errs = append(errs, ValidationError{Code: "invalidGoalPiece", PieceIndex: i})
+		}
+	}
+
+	// 目标布局需要为每个棋子给出位置，且同样不能越界或重叠
+	if len(goal.Layout) > 0 {
+		if len(goal.Layout) != len(game.PieceList) {
+			errs = append(errs, ValidationError{Code: "invalidTargetLayout", PieceIndex: -1})
+		} else {
+			board := make([]bool, int(game.BoardRows)*int(game.BoardCols))
+			for i, pos := range goal.Layout {
+				if !validShapes[i] {
+					continue
+				}
+				shape := game.PieceList[i].Shape
+				if len(pos) != 2 || !shapeInBoard(game, shape, pos) || onWall(walls, game.BoardCols, shape, pos) ||
+					!markShape(board, game.BoardCols, shape, pos) {
+					errs = append(errs, ValidationError{Code: "invalidTargetLayout", PieceIndex: i})
+				}
+			}
+		}
+	}
+
+	// 区域目标的形状必须有对应的棋子，区域不能超出棋盘
+	for _, region := range goal.Regions {
+		valid := validShape(region.Shape) && len(region.Cells) > 0
+		for _, cell := range region.Cells {
+			if len(cell) != 2 || cell[0] < 0 || cell[1] < 0 || cell[0] >= game.BoardRows || cell[1] >= game.BoardCols {
+				valid = false
+			}
+		}
+		hasPiece := false
+		if valid {
+			shapeStr := shape2Str(region.Shape)
+			for i, piece := range game.PieceList {
+				if validShapes[i] && shape2Str(piece.Shape) == shapeStr {
+					hasPiece = true
+				}
+			}
+		}
+		if !valid || !hasPiece {
+			errs = append(errs, ValidationError{Code: "invalidGoalRegion", PieceIndex: -1})
+		}
+	}
+	return errs
+}
+
+// validShape 形状是非空的矩形，且至少占据一格
+func validShape(shape Shape) bool {
+	if len(shape) == 0 || len(shape[0]) == 0 {
+		return false
+	}
+	for _, row := range shape {
+		if len(row) != len(shape[0]) {
+			return false
+		}
+	}
+	return shapeCellCount(shape) > 0
+}
+
+func shapeInBoard(game GameData, shape Shape, pos Pos) bool {
+	return pos[0] >= 0 && pos[1] >= 0 &&
+		int(pos[0])+len(shape) <= int(game.BoardRows) &&
+		int(pos[1])+len(shape[0]) <= int(game.BoardCols)
+}
+
+// onWall 形状是否压在墙上
+func onWall(walls []bool, cols int16, shape Shape, pos Pos) bool {
+	for rowIndex, row := range shape {
+		for colIndex, grid := range row {
+			if grid && walls[(int(pos[0])+rowIndex)*int(cols)+int(pos[1])+colIndex] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markShape 在棋盘上标记形状占据的格子，与已标记的格子重叠时返回false
+func markShape(board []bool, cols int16, shape Shape, pos Pos) bool {
+	ok := true
+	for rowIndex, row := range shape {
+		for colIndex, grid := range row {
+			if !grid {
+				continue
+			}
+			index := (int(pos[0])+rowIndex)*int(cols) + int(pos[1]) + colIndex
+			if board[index] {
+				ok = false
+			}
+			board[index] = true
+		}
+	}
+	return ok
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestValidate 每种错误返回对应的错误类型和棋子索引
+func TestValidate(t *testing.T) {
+	square := Shape{{true, true}, {true, true}}
+	cases := []struct {
+		name   string
+		modify func(game *GameData)
+		want   []ValidationError
+	}{
+		{"valid", func(game *GameData) {}, []ValidationError{}},
+		{"board size", func(game *GameData) { game.BoardRows = 0 }, []ValidationError{{"invalidBoardSize", -1}}},
+		{"huge board", func(game *GameData) { game.BoardRows, game.BoardCols = 200, 200 }, []ValidationError{{"invalidBoardSize", -1}}},
+		{"no pieces", func(game *GameData) { game.PieceList = nil }, []ValidationError{{"noPieces", -1}}},
+		{"wall outside", func(game *GameData) { game.Walls = []Pos{{5, 0}} }, []ValidationError{{"invalidWall", -1}}},
+		{"empty shape", func(game *GameData) { game.PieceList[3].Shape = Shape{} }, []ValidationError{{"invalidPieceShape", 3}}},
+		{"ragged shape", func(game *GameData) { game.PieceList[3].Shape = Shape{{true}, {true, true}} }, []ValidationError{{"invalidPieceShape", 3}}},
+		{"blank shape", func(game *GameData) { game.PieceList[6].Shape = Shape{{false}} }, []ValidationError{{"invalidPieceShape", 6}}},
+		{"out of board", func(game *GameData) { game.PieceList[9].Position = Pos{4, 4} }, []ValidationError{{"pieceOutOfBoard", 9}}},
+		{"short position", func(game *GameData) { game.PieceList[9].Position = Pos{4} }, []ValidationError{{"pieceOutOfBoard", 9}}},
+		{"on wall", func(game *GameData) { game.Walls = []Pos{{4, 0}} }, []ValidationError{{"pieceOnWall", 8}}},
+		{"overlap", func(game *GameData) { game.PieceList[7].Position = Pos{3, 1} }, []ValidationError{{"pieceOverlap", 7}}},
+		{"several errors", func(game *GameData) {
+			game.PieceList[7].Position = Pos{3, 1}
+			game.PieceList[9].Position = Pos{9, 9}
+		}, []ValidationError{{"pieceOverlap", 7}, {"pieceOutOfBoard", 9}}},
+		{"king index", func(game *GameData) { game.KingPieceIndex = 10 }, []ValidationError{{"kingIndexOutOfRange", -1}}},
+		{"no king", func(game *GameData) { game.KingPieceIndex = -1 }, []ValidationError{{"kingIndexOutOfRange", -1}}},
+		{"king win position", func(game *GameData) { game.KingWinPos = Pos{4, 1} }, []ValidationError{{"invalidKingWinPos", 0}}},
+		{"king wins on wall", func(game *GameData) { game.Walls = []Pos{{4, 2}} }, []ValidationError{{"invalidKingWinPos", 0}}},
+		{"goal without king", func(game *GameData) {
+			game.KingPieceIndex = -1
+			game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{3, 1}}}}
+		}, []ValidationError{}},
+		{"goal king index", func(game *GameData) {
+			game.KingPieceIndex = -2
+			game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{3, 1}}}}
+		}, []ValidationError{{"kingIndexOutOfRange", -1}}},
+		{"goal piece index", func(game *GameData) {
+			game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 10, Position: Pos{3, 1}}}}
+		}, []ValidationError{{"invalidGoalPiece", -1}}},
+		{"goal piece position", func(game *GameData) {
+			game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{4, 1}}}}
+		}, []ValidationError{{"invalidGoalPiece", 0}}},
+		{"target layout length", func(game *GameData) {
+			game.Goal = &Goal{Layout: []Pos{{0, 0}}}
+		}, []ValidationError{{"invalidTargetLayout", -1}}},
+		{"target layout overlap", func(game *GameData) {
+			layout := []Pos{}
+			for _, piece := range game.PieceList {
+				layout = append(layout, piece.Position)
+			}
+			layout[2] = Pos{0, 0}
+			game.Goal = &Goal{Layout: layout}
+		}, []ValidationError{{"invalidTargetLayout", 2}}},
+		{"region shape", func(game *GameData) {
+			game.Goal = &Goal{Regions: []RegionGoal{{Shape: Shape{{true, true, true}}, Cells: []Pos{{0, 0}}}}}
+		}, []ValidationError{{"invalidGoalRegion", -1}}},
+		{"region cells", func(game *GameData) {
+			game.Goal = &Goal{Regions: []RegionGoal{{Shape: square, Cells: []Pos{{5, 0}}}}}
+		}, []ValidationError{{"invalidGoalRegion", -1}}},
+		{"region", func(game *GameData) {
+			game.Goal = &Goal{Regions: []RegionGoal{{Shape: square, Cells: []Pos{{3, 1}}}}}
+		}, []ValidationError{}},
+	}
+	for _, c := range cases {
+		game := classicGame()
+		c.modify(&game)
+		if errs := Validate(game); !reflect.DeepEqual(errs, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, errs, c.want)
+		}
+	}
+}
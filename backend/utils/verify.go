@@ -0,0 +1,51 @@
+package utils
+
+import "errors"
+
+// VerifyResult 解法的检查结果
+type VerifyResult struct {
+	Valid       bool  `json:"valid"`       // 每一步都能走
+	Solved      bool  `json:"solved"`      // 走完之后满足获胜条件
+	InvalidStep int   `json:"invalidStep"` // 第一个不能走的步的序号，都能走时为-1
+	Moves       int   `json:"moves"`       // 实际走了的步数，同一棋子连续移动算一步
+	Positions   []Pos `json:"positions"`   // 走完（或停在不能走的步之前）时每个棋子的位置
+}
+
+// Verify 从开局依次重放解法，逐步检查碰撞，最后按获胜条件判断是否获胜
+// 每一步的 Direction 可以是同一棋子连续多次移动的合计，只要棋子能在其他棋子不动时走到目标位置即可；
+// 带有路线时按路线逐格检查
+func Verify(game GameData, steps []Step) (VerifyResult, error) {
+	result := VerifyResult{
+		InvalidStep: -1,
+	}
+	if len(Validate(game)) > 0 {
+		return result, errors.New("invalid game data")
+	}
+	gs := replaySolve(game)
+	played := len(steps)
+	for i, step := range steps {
+		if !gs.TryStep(step) {
+			result.InvalidStep = i
+			played = i
+			break
+		}
+	}
+	result.Moves = CountMoves(steps[:played])
+	result.Valid = result.InvalidStep < 0
+	result.Solved = result.Valid && gs.IsWin()
+	result.Positions = gs.Positions()
+	return result, nil
+}
+
+// CountMoves 步数，同一棋子连续移动算一步，试玩和检查解法都按这个计步
+func CountMoves(steps []Step) int {
+	moves := 0
+	lastPiece := int16(-1)
+	for _, step := range steps {
+		if step.PieceIndex != lastPiece {
+			moves++
+			lastPiece = step.PieceIndex
+		}
+	}
+	return moves
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestVerify 完整的解法获胜，截断的解法合法但没有获胜
+func TestVerify(t *testing.T) {
+	solved, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify, err := Verify(classicGame(), solved.Steps)
+	if err != nil || !verify.Valid || !verify.Solved || verify.InvalidStep != -1 || verify.Moves != 81 {
+		t.Errorf("full solution: %+v, %v", verify, err)
+	}
+	verify, _ = Verify(classicGame(), solved.Steps[:80])
+	if !verify.Valid || verify.Solved || verify.Moves != 80 {
+		t.Errorf("truncated solution: %+v", verify)
+	}
+	if !reflect.DeepEqual(verify.Positions, layoutAfter(t, classicGame(), solved.Steps, 80)) {
+		t.Errorf("positions %v", verify.Positions)
+	}
+
+	// 同一棋子分两次走算一步
+	game := lineGame(4)
+	game.Goal = &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 3}}}}
+	split := []Step{{PieceIndex: 0, Direction: []int16{0, 1}}, {PieceIndex: 0, Direction: []int16{0, 2}}}
+	if verify, _ := Verify(game, split); !verify.Solved || verify.Moves != 1 {
+		t.Errorf("split move: %+v", verify)
+	}
+
+	if _, err := Verify(GameData{}, nil); err == nil {
+		t.Error("verified an invalid layout")
+	}
+}
+
+// TestVerifyInvalidStep 返回第一个不能走的步的序号，位置停在这一步之前
+func TestVerifyInvalidStep(t *testing.T) {
+	solved, err := solveGame(t, classicGame(), SolveOptions{Metric: MetricPiece})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每种错误的步由走到这一步之前的位置生成
+	cases := []struct {
+		name string
+		step func(positions []Pos) Step
+	}{
+		{"onto the king", func(positions []Pos) Step {
+			return Step{PieceIndex: 1, Direction: []int16{positions[0][0] - positions[1][0], positions[0][1] - positions[1][1]}}
+		}},
+		{"outside the board", func(positions []Pos) Step {
+			return Step{PieceIndex: 0, Direction: []int16{-positions[0][0] - 1, 0}}
+		}},
+		{"no move", func(positions []Pos) Step {
+			return Step{PieceIndex: 0, Direction: []int16{0, 0}}
+		}},
+		{"piece index", func(positions []Pos) Step {
+			return Step{PieceIndex: 10, Direction: []int16{0, 1}}
+		}},
+		{"short direction", func(positions []Pos) Step {
+			return Step{PieceIndex: 0, Direction: []int16{1}}
+		}},
+	}
+	for _, index := range []int{0, 10, 80} {
+		positions := layoutAfter(t, classicGame(), solved.Steps, index)
+		for _, c := range cases {
+			steps := append([]Step{}, solved.Steps[:index]...)
+			steps = append(steps, c.step(positions))
+			steps = append(steps, solved.Steps[index:]...)
+			verify, err := Verify(classicGame(), steps)
+			if err != nil || verify.Valid || verify.Solved || verify.InvalidStep != index {
+				t.Errorf("%s at %d: %+v, %v", c.name, index, verify, err)
+				continue
+			}
+			if !reflect.DeepEqual(verify.Positions, positions) {
+				t.Errorf("%s at %d: positions %v, want %v", c.name, index, verify.Positions, positions)
+			}
+		}
+	}
+}
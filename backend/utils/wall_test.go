@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+)
+
+// TestWalls 棋子不能穿过墙，只能绕行
+func TestWalls(t *testing.T) {
+	// 2行3列，第一行中间是墙，棋子从左上角绕到右上角
+	game := GameData{
+		BoardRows:      2,
+		BoardCols:      3,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		Walls:          []Pos{{0, 1}},
+		PieceList:      []Piece{{Shape{{true}}, Pos{0, 0}}},
+		Goal:           &Goal{Pieces: []PieceGoal{{PieceIndex: 0, Position: Pos{0, 2}}}},
+	}
+	cases := []struct {
+		metric Metric
+		length int
+	}{
+		{MetricStep, 4},
+		{MetricStraight, 3},
+		{MetricPiece, 1},
+	}
+	for _, c := range cases {
+		result, err := solveGame(t, game, SolveOptions{Metric: c.metric})
+		if err != nil || result.Length != c.length {
+			t.Errorf("%s: length %d, %v, want %d", c.metric, result.Length, err, c.length)
+			continue
+		}
+		verify, err := Verify(game, result.Steps)
+		if err != nil || !verify.Solved {
+			t.Errorf("%s: solution does not verify", c.metric)
+		}
+	}
+
+	// 沿路线直接穿过墙不能走
+	through := []Step{{PieceIndex: 0, Direction: []int16{0, 2}, Path: [][]int16{{0, 1}, {0, 1}}}}
+	if verify, _ := Verify(game, through); verify.Valid {
+		t.Error("moved through a wall")
+	}
+
+	// 整列是墙时无解
+	game.Walls = []Pos{{0, 1}, {1, 1}}
+	if result, err := solveGame(t, game, SolveOptions{}); err == nil {
+		t.Errorf("solved across a wall in %d moves", result.Length)
+	}
+}
+
+// TestShapedPieces 非矩形的棋子只占据形状中的格子，空缺处可以放其他棋子
+func TestShapedPieces(t *testing.T) {
+	// 2行3列，L形棋子占左边三格，单格棋子在它的缺口里，要移到右下角
+	game := GameData{
+		BoardRows:      2,
+		BoardCols:      3,
+		KingPieceIndex: -1,
+		KingWinPos:     Pos{-1, -1},
+		PieceList: []Piece{
+			{Shape{{true, true}, {true, false}}, Pos{0, 0}},
+			{Shape{{true}}, Pos{1, 1}},
+		},
+		Goal: &Goal{Pieces: []PieceGoal{{PieceIndex: 1, Position: Pos{1, 2}}}},
+	}
+	result, err := solveGame(t, game, SolveOptions{Metric: MetricStep})
+	if err != nil || result.Length != 1 {
+		t.Fatalf("length %d, %v", result.Length, err)
+	}
+
+	// L形棋子向右移动一格时，缺口中的棋子挡住了它
+	blocked := []Step{{PieceIndex: 0, Direction: []int16{0, 1}}}
+	if verify, _ := Verify(game, blocked); verify.Valid {
+		t.Error("moved the L piece over another piece")
+	}
+}
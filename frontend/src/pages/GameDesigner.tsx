@@ -17,6 +17,8 @@ import { PieceItem } from '@/src/components/PieceItem';
 import { currentGame } from '@/src/stores/currentGame';
 import './GameDesigner.less'
 
+let solveJobSeq = 0 // 求解任务ID，用于取消和区分进度
+
 
 interface ContextMenuData {
     x: number;
@@ -42,6 +44,7 @@ function GameDesignerPage() {
         contextMenuData?: ContextMenuData,
         door?: Door;
         solveLoading: boolean;
+        solveProgress?: SolveProgress;
     }>({
         rows: 5,
         cols: 4,
@@ -55,6 +58,7 @@ function GameDesignerPage() {
         contextMenuData: undefined,
         door: undefined,
         solveLoading: false,
+        solveProgress: undefined,
     })
 
     const alertRef = useRef<MyAlertRef>({} as MyAlertRef)
@@ -69,6 +73,7 @@ function GameDesignerPage() {
     })
 
     const mouseOverPosStrRef = useRef<number[]>([-1, -1])
+    const solveJobRef = useRef<number>(0)
 
     useEffect(() => {
         const copyGame = (ev: globalThis.KeyboardEvent) => {
@@ -549,28 +554,58 @@ function GameDesignerPage() {
             throw new Error('No King Piece')
         }
         const gameData = GameUtils.makeGameData(state.rows, state.cols, state.pieceList, state.kingPieceIndex, state.door as Door);
+        // 保留原布局的获胜条件和墙
+        if (currentGame.gameData?.goal) {
+            gameData.goal = currentGame.gameData.goal
+        }
+        if (currentGame.gameData?.walls) {
+            gameData.walls = currentGame.gameData.walls.filter(([row, col]) => row < state.rows && col < state.cols)
+        }
         currentGame.gameData = gameData;
         currentGame.game = {
             ...currentGame.game,
             gameShape: GameUtils.gameData2GameShape(gameData),
+            goal: gameData.goal ? JSON.stringify(gameData.goal) : '',
             md5: GameUtils.gameData2Md5(gameData),
         }
         return gameData;
     })
 
+    /**
+     * 布局检查错误的说明
+     */
+    const validationDetail = (validationErrors?: ValidationError[]) => {
+        return (validationErrors || []).map((err) => {
+            const message = t(`GameDesigner.${err.code}`)
+            return err.pieceIndex >= 0 ? `${message} #${err.pieceIndex + 1}` : message
+        }).join('; ')
+    }
+
     /**
      * 求解布局
      */
     const solve = async () => {
         const gameData = makeGameData()
-        setState({ solveLoading: true })
-        const res = await GameService.solve(gameData)
+        const jobId = ++solveJobSeq
+        solveJobRef.current = jobId
+        setState({ solveLoading: true, solveProgress: undefined })
+        window.runtime.EventsOn('gameSolveProgress', (solveProgress: SolveProgress) => {
+            if (solveProgress.jobId === jobId) {
+                setState({ solveProgress })
+            }
+        })
+        const res = await GameService.solve({ jobId, gameId: currentGame.game.id, gameData, metric: 'piece' })
+        window.runtime.EventsOff('gameSolveProgress')
         setState({
             solveLoading: false,
+            solveProgress: undefined,
         })
         if (!res.success && res.errMessage) {
             alertRef.current.open({
-                message: t(`GameDesigner.${res.errMessage}`),
+                message: t(`GameDesigner.${res.errMessage}`, {
+                    detail: validationDetail(res.validationErrors),
+                    explored: res.forwardExplored + res.backwardExplored,
+                }),
                 type: 'warning'
             })
             return
@@ -662,8 +697,9 @@ function GameDesignerPage() {
     })
 
     const save = useMemoizedFn(async () => {
-        makeGameData()
-        const game = currentGame.game
+        const gameData = makeGameData()
+        // 获胜条件中的棋子索引按设计器中的顺序，由后端按王棋在前的布局数据调整后保存
+        const game = { ...currentGame.game, gameData }
         if (!game.id) {
             createGameDialogRef.current.open({
                 onChange: async ({
@@ -677,7 +713,7 @@ function GameDesignerPage() {
                     })
                     if (!res.success && res.errMessage) {
                         alertRef.current.open({
-                            message: t(`GameDesigner.${res.errMessage}`, { name: res.game.name }),
+                            message: t(`GameDesigner.${res.errMessage}`, { name: res.game.name, detail: validationDetail(res.validationErrors) }),
                             type: 'error',
                             duration: 5000,
                         })
@@ -694,7 +730,7 @@ function GameDesignerPage() {
             const res = await GameService.save(game)
             if (!res.success && res.errMessage) {
                 alertRef.current.open({
-                    message: t(`GameDesigner.${res.errMessage}`),
+                    message: t(`GameDesigner.${res.errMessage}`, { detail: validationDetail(res.validationErrors) }),
                     type: 'error',
                 })
                 return
@@ -751,6 +787,20 @@ function GameDesignerPage() {
                         >
                             {t("GameDesigner.solve")}
                         </MyButton>
+                        {state.solveLoading ? (
+                            <MyButton
+                                className='btn'
+                                variant="contained"
+                                onClick={() => GameService.solveCancel({ jobId: solveJobRef.current })}
+                            >
+                                {t("GameDesigner.cancelSolve")}
+                            </MyButton>
+                        ) : null}
+                        {state.solveProgress ? (
+                            <span className='solveProgress'>
+                                {t("GameDesigner.solveProgress", state.solveProgress)}
+                            </span>
+                        ) : null}
                         <MyButton
                             className='btn'
                             variant="contained"
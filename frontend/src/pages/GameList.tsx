@@ -78,7 +78,7 @@ function GameListPage() {
     setState({
       total: data.total,
       list: (data.games || []).map(game => {
-        const gameData = GameUtils.gameShape2GameData(game.gameShape)
+        const gameData = GameUtils.gameShape2GameData(game.gameShape, game.goal)
         const cover = GameUtils.gameData2Cover(gameData)
         return {
           gameData,
@@ -219,6 +219,7 @@ function GameListPage() {
         message: t("GameList.importSuccess", {
           count: res.count,
           repeatCount: res.repeatCount,
+          invalidCount: res.invalidCount,
         }), type: 'success'
       })
       loadGameList()
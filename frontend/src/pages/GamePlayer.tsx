@@ -8,6 +8,7 @@ import { useNavigate } from "react-router-dom";
 import { Layer, Rect, Stage } from 'react-konva';
 import { PieceItem } from '@/src/components/PieceItem';
 import GameUtils from "@/src/utils/game";
+import { usePieceMove } from "@/src/utils/usePieceMove";
 import { useEffect, useMemo, useRef } from "react";
 import { currentGame } from "@/src/stores/currentGame";
 import { useTranslation } from "react-i18next";
@@ -51,6 +52,7 @@ export default function GamePlayerPage() {
 
   const alertRef = useRef<MyAlertRef>({} as MyAlertRef)
   const lastDragMove = useRef<{ x: number, y: number }>({ x: 0, y: 0 })
+  const { moving, movePiece } = usePieceMove()
 
   useEffect(() => {
     const onResize = () => {
@@ -66,8 +68,10 @@ export default function GamePlayerPage() {
     }
   }, [state.cols, state.rows])
 
-  useEffect(() => {
-    // 构造棋盘，-1为空，其他值为棋子索引
+  /**
+   * 构造棋盘，-1为空，其他值为棋子索引
+   */
+  const makeBoard = useMemoizedFn(() => {
     const board = Array(state.rows).fill(0).map(() => Array(state.cols).fill(-1))
     for (let i = 0; i < state.pieceList.length; i++) {
       const piece = state.pieceList[i]
@@ -81,6 +85,11 @@ export default function GamePlayerPage() {
         }
       }
     }
+    return board
+  })
+
+  useEffect(() => {
+    const board = makeBoard()
     // 计算每个棋子可拖动的位置
     const piecesNextPostions = Array(state.pieceList.length).fill(0).map(() => []) as number[][][];
     for (let i = 0; i < state.pieceList.length; i++) {
@@ -223,7 +232,7 @@ export default function GamePlayerPage() {
       e.target.y(0)
     }
     resetDragData()
-    if (rowDiff === 0 && colDiff === 0) {
+    if ((rowDiff === 0 && colDiff === 0) || moving) {
       return
     }
     const piece = state.pieceList[pieceIndex]
@@ -235,10 +244,13 @@ export default function GamePlayerPage() {
       return
     }
     const direction = [rowDiff, colDiff]
+    // 记录拖动经过的格子，悔棋和重做时沿原路逐格移动
+    const path = GameUtils.calcPiecePath(makeBoard(), piece, pieceIndex, newPosition)
     setState(produce(draft => {
       draft.solution.push({
         pieceIndex,
-        direction: direction,
+        direction: direction as [number, number],
+        path,
       })
       draft.undoSolution = []
       draft.stepIndex++
@@ -251,7 +263,24 @@ export default function GamePlayerPage() {
 
   })
 
+  /**
+   * 棋子按方向移动一格
+   */
+  const moveCell = useMemoizedFn((pieceIndex: number, direction: [number, number]) => {
+    setState(produce(draft => {
+      const piece = draft.pieceList[pieceIndex]
+      draft.pieceList[pieceIndex] = GameUtils.pieceCalcInBoard({
+        shape: piece.shape,
+        position: [piece.position[0] + direction[0], piece.position[1] + direction[1]],
+        inBoard: [],
+      }, state.rows, state.cols)
+    }))
+  })
+
   const restart = useMemoizedFn(() => {
+    if (moving) {
+      return
+    }
     setState({
       stepIndex: 0,
       solution: [],
@@ -261,46 +290,30 @@ export default function GamePlayerPage() {
   })
 
   const undo = useMemoizedFn(() => {
+    if (moving) {
+      return
+    }
     const step = state.solution[state.stepIndex - 1]
-    const pieceIndex = step.pieceIndex
-    const piece = state.pieceList[pieceIndex]
     const stepIndex = state.stepIndex - 1
-    const undoSolution = [step, ...state.undoSolution]
-
-    const solution = state.solution.slice(0, stepIndex)
-    const newPiece = GameUtils.pieceCalcInBoard({
-      shape: piece.shape,
-      position: [piece.position[0] - step.direction[0], piece.position[1] - step.direction[1]],
-      inBoard: [],
-    }, state.rows, state.cols)
-    setState(produce(draft => {
-      draft.stepIndex = stepIndex
-      draft.undoSolution = undoSolution
-      draft.solution = solution
-      draft.pieceList[pieceIndex] = newPiece
-    }))
-
+    setState({
+      stepIndex,
+      undoSolution: [step, ...state.undoSolution],
+      solution: state.solution.slice(0, stepIndex),
+    })
+    movePiece(GameUtils.stepPath(step, true), direction => moveCell(step.pieceIndex, direction))
   })
 
   const redo = useMemoizedFn(() => {
-    const stepIndex = state.stepIndex + 1
-    const undoSolution = state.undoSolution.slice(1)
+    if (moving) {
+      return
+    }
     const step = state.undoSolution[0]
-    const pieceIndex = step.pieceIndex
-    const piece = state.pieceList[pieceIndex]
-    const solution = [...state.solution, step]
-    const newPiece = GameUtils.pieceCalcInBoard({
-      shape: piece.shape,
-      position: [piece.position[0] + step.direction[0], piece.position[1] + step.direction[1]],
-      inBoard: [],
-    }, state.rows, state.cols)
-    setState(produce(draft => {
-      draft.stepIndex = stepIndex
-      draft.undoSolution = undoSolution
-      draft.solution = solution
-      draft.pieceList[pieceIndex] = newPiece
-    }))
-
+    setState({
+      stepIndex: state.stepIndex + 1,
+      undoSolution: state.undoSolution.slice(1),
+      solution: [...state.solution, step],
+    })
+    movePiece(GameUtils.stepPath(step), direction => moveCell(step.pieceIndex, direction))
   })
 
 
@@ -359,7 +372,7 @@ export default function GamePlayerPage() {
                       piece={piece}
                       color={pieceIndex === state.kingPieceIndex ? '#fffb00' : '#0ed07e'}
                       gridSize={state.gridSize}
-                      draggable={state.piecesNextPostions[pieceIndex]?.length > 0}
+                      draggable={state.piecesNextPostions[pieceIndex]?.length > 0 && !moving}
                       onDragStart={handleDragStart}
                       dragBoundFunc={piecesDragBoundFuncs[pieceIndex]}
                       onDragEnd={(e) => handleDragEnd(e, pieceIndex)}
@@ -372,10 +385,10 @@ export default function GamePlayerPage() {
             </div>
           </div>
           <div className='stepActions'>
-            <MyButton onClick={restart}>{t("GamePlayer.restart")}</MyButton>
+            <MyButton onClick={restart} disabled={moving}>{t("GamePlayer.restart")}</MyButton>
             <span className='stepIndex'>{state.stepIndex}步</span>
-            <MyButton onClick={undo} disabled={state.stepIndex === 0}>{t("GamePlayer.undo")}</MyButton>
-            <MyButton onClick={redo} disabled={state.undoSolution.length === 0}>{t("GamePlayer.redo")}</MyButton>
+            <MyButton onClick={undo} disabled={state.stepIndex === 0 || moving}>{t("GamePlayer.undo")}</MyButton>
+            <MyButton onClick={redo} disabled={state.undoSolution.length === 0 || moving}>{t("GamePlayer.redo")}</MyButton>
           </div>
         </div>
       </div>
@@ -5,6 +5,7 @@ import { useNavigate } from "react-router-dom";
 import { Layer, Rect, Stage } from 'react-konva';
 import { PieceItem } from '@/src/components/PieceItem';
 import GameUtils from "@/src/utils/game";
+import { usePieceMove } from "@/src/utils/usePieceMove";
 import { useEffect, useMemo } from "react";
 import { MyButton } from "@/src/components/MyButton";
 import { currentGame } from "@/src/stores/currentGame";
@@ -56,35 +57,37 @@ export default function GameSolutionPage() {
   })
 
 
+  const { moving, movePiece } = usePieceMove()
+
+  /**
+   * 棋子按方向移动一格
+   */
+  const moveCell = useMemoizedFn((pieceIndex: number, direction: [number, number]) => {
+    setState(produce(draft => {
+      const piece = draft.pieceList[pieceIndex]
+      draft.pieceList[pieceIndex] = GameUtils.pieceCalcInBoard({
+        shape: piece.shape,
+        position: [piece.position[0] + direction[0], piece.position[1] + direction[1]],
+        inBoard: [],
+      }, state.rows, state.cols)
+    }))
+  })
+
   const prevStep = useMemoizedFn(() => {
-    if (state.stepIndex > 0) {
+    if (state.stepIndex > 0 && !moving) {
       const stepIndex = state.stepIndex - 1
       const step = state.solution[stepIndex]
-      const piece = state.pieceList[step.pieceIndex]
-      setState(produce(draft => {
-        draft.stepIndex = stepIndex
-        draft.pieceList[step.pieceIndex] = GameUtils.pieceCalcInBoard({
-          shape: piece.shape,
-          position: [piece.position[0] - step.direction[0], piece.position[1] - step.direction[1]],
-          inBoard: [],
-        }, state.rows, state.cols)
-      }))
+      setState({ stepIndex })
+      movePiece(GameUtils.stepPath(step, true), direction => moveCell(step.pieceIndex, direction))
     }
   })
 
   const nextStep = useMemoizedFn(() => {
-    if (state.stepIndex < state.solution.length) {
+    if (state.stepIndex < state.solution.length && !moving) {
       const stepIndex = state.stepIndex + 1
       const step = state.solution[stepIndex - 1]
-      const piece = state.pieceList[step.pieceIndex]
-      setState(produce(draft => {
-        draft.stepIndex = stepIndex
-        draft.pieceList[step.pieceIndex] = GameUtils.pieceCalcInBoard({
-          shape: piece.shape,
-          position: [piece.position[0] + step.direction[0], piece.position[1] + step.direction[1]],
-          inBoard: [],
-        }, state.rows, state.cols)
-      }))
+      setState({ stepIndex })
+      movePiece(GameUtils.stepPath(step), direction => moveCell(step.pieceIndex, direction))
     }
   })
 
@@ -160,9 +163,9 @@ export default function GameSolutionPage() {
             </div>
           </div>
           <div className='stepActions'>
-            <MyButton onClick={prevStep}>{t("GameSolution.prevStep")}</MyButton>
+            <MyButton onClick={prevStep} disabled={moving}>{t("GameSolution.prevStep")}</MyButton>
             <span className='stepIndex'>{state.stepIndex}/{state.solution.length}</span>
-            <MyButton onClick={nextStep}>{t("GameSolution.nextStep")}</MyButton>
+            <MyButton onClick={nextStep} disabled={moving}>{t("GameSolution.nextStep")}</MyButton>
           </div>
         </div>
       </div>
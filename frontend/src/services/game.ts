@@ -5,4 +5,13 @@ export default class GameService {
   static impord = window.go.app.App.GameImport;
   static expord = window.go.app.App.GameExport;
   static solve = window.go.app.App.GameSolve;
+  static solveCancel = window.go.app.App.GameSolveCancel;
+  static analyze = window.go.app.App.GameAnalyze;
+  static hardest = window.go.app.App.GameHardest;
+  static generate = window.go.app.App.GameGenerate;
+  static hint = window.go.app.App.GameHint;
+  static verifySolution = window.go.app.App.GameVerifySolution;
+  static countSolutions = window.go.app.App.GameCountSolutions;
+  static solverStatus = window.go.app.App.SolverStatus;
+  static validate = window.go.app.App.GameValidate;
 }
@@ -0,0 +1,7 @@
+export default class PlayService {
+  static start = window.go.app.App.PlayStart;
+  static move = window.go.app.App.PlayMove;
+  static undo = window.go.app.App.PlayUndo;
+  static redo = window.go.app.App.PlayRedo;
+  static state = window.go.app.App.PlayState;
+}
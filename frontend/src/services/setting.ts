@@ -0,0 +1,4 @@
+export default class SettingService {
+  static get = window.go.app.App.SettingGet;
+  static save = window.go.app.App.SettingSave;
+}
@@ -24,6 +24,7 @@ interface GameImportRes {
   success: boolean;
   errMessage: string;
   repeatCount: number;
+  invalidCount: number;
   count: number;
 }
 
@@ -39,16 +40,302 @@ interface GameListRes {
   total: number;
 }
 
+interface ValidationError {
+  code: string;
+  pieceIndex: number;
+}
+
 interface GameSaveRes {
   success: boolean;
   errMessage: string;
   game: Game;
+  validationErrors: ValidationError[];
+}
+
+type Metric = 'step' | 'piece' | 'straight';
+
+type Algorithm = 'bfs' | 'bidirectional' | 'astar' | 'idastar' | 'parallel' | 'external';
+
+interface GameSolveReq {
+  jobId?: number;
+  gameId?: number;
+  gameData?: GameData;
+  metric?: Metric;
+  algorithm?: Algorithm;
+  heuristic?: 'goal' | 'zero' | 'weighted';
+  maxStates?: number;
+  maxTime?: number;
+  maxMemory?: number;
+  workers?: number;
+  noSymmetry?: boolean;
+  scratchDir?: string;
+  memoryBudget?: number;
+  force?: boolean;
 }
 
 interface GameSolveRes {
   success: boolean;
   errMessage: string;
+  jobId: number;
   solution: Solution;
+  metric: Metric;
+  algorithm: Algorithm;
+  length: number;
+  optimal: boolean;
+  forwardExplored: number;
+  backwardExplored: number;
+  stats: SolveStats;
+  limit: '' | 'states' | 'time' | 'memory';
+  cached: boolean;
+  validationErrors: ValidationError[];
+}
+
+interface SolveStats {
+  generated: number;
+  duplicates: number;
+  peakFrontier: number;
+  maxDepth: number;
+  elapsed: number;
+  peakMemory: number;
+}
+
+interface GameSolveStats {
+  metric: Metric;
+  algorithm: Algorithm;
+  length: number;
+  optimal: boolean;
+  stats: SolveStats;
+}
+
+interface GameSolveCancelReq {
+  jobId?: number;
+}
+
+interface GameSolveCancelRes {
+  success: boolean;
+  count: number;
+}
+
+interface GameAnalyzeReq {
+  jobId?: number;
+  gameId?: number;
+  gameData?: GameData;
+  metric?: Metric;
+  maxStates?: number;
+}
+
+interface AnalyzeResult {
+  metric: Metric;
+  reachable: number;
+  winning: number;
+  startDistance: number;
+  maxDistance: number;
+  histogram: number[];
+  unsolvable: number;
+  optimalSolutions: number;
+}
+
+interface GameAnalyzeRes {
+  success: boolean;
+  errMessage: string;
+  jobId: number;
+  analysis: AnalyzeResult;
+  validationErrors: ValidationError[];
+}
+
+interface GameCountSolutionsReq {
+  jobId?: number;
+  gameId?: number;
+  gameData?: GameData;
+  maxStates?: number;
+}
+
+interface SolutionCount {
+  metric: Metric;
+  moves: number;
+  optimalSolutions: number;
+  unique: boolean;
+  reachable: number;
+  deadEnds: number;
+}
+
+interface GameCountSolutionsRes {
+  success: boolean;
+  errMessage: string;
+  jobId: number;
+  count: SolutionCount;
+  validationErrors: ValidationError[];
+}
+
+interface GameHardestReq {
+  jobId?: number;
+  gameId?: number;
+  gameData?: GameData;
+  metric?: Metric;
+  maxStates?: number;
+}
+
+interface HardestResult {
+  gameData: GameData;
+  metric: Metric;
+  length: number;
+  ties: number;
+}
+
+interface GameHardestRes {
+  success: boolean;
+  errMessage: string;
+  jobId: number;
+  hardest: HardestResult;
+  validationErrors: ValidationError[];
+}
+
+interface GenerateOptions {
+  boardRows: number;
+  boardCols: number;
+  door: Door;
+  kingShape: Shape;
+  shapePool: Shape[];
+  emptyCells?: number;
+  minLength: number;
+  maxLength?: number;
+  metric?: Metric;
+  maxStates?: number;
+  maxMemory?: number;
+  seed: number;
+  timeBudget?: number;
+}
+
+interface GameGenerateReq extends GenerateOptions {
+  jobId?: number;
+}
+
+interface GenerateResult {
+  gameData: GameData;
+  metric: Metric;
+  length: number;
+  attempts: number;
+  seed: number;
+}
+
+interface GameGenerateRes {
+  success: boolean;
+  errMessage: string;
+  jobId: number;
+  generated: GenerateResult;
+}
+
+interface GameHintReq {
+  jobId?: number;
+  gameId: number;
+  gameData?: GameData;
+  metric?: Metric;
+  positions: Pos[];
+}
+
+interface HintResult {
+  metric: Metric;
+  solvable: boolean;
+  distance: number;
+  step: Step;
+}
+
+interface GameHintRes {
+  success: boolean;
+  errMessage: string;
+  jobId: number;
+  hint: HintResult;
+  validationErrors: ValidationError[];
+}
+
+interface PlayStartReq {
+  gameId: number;
+  metric?: Metric;
+}
+
+interface PlaySessionState {
+  gameId: number;
+  metric: Metric;
+  positions: Pos[];
+  moves: number;
+  steps: Step[];
+  canUndo: boolean;
+  canRedo: boolean;
+  elapsed: number;
+  win: boolean;
+}
+
+interface PlaySessionRes {
+  success: boolean;
+  errMessage: string;
+  session: PlaySessionState;
+}
+
+interface GameVerifySolutionReq {
+  gameId?: number;
+  gameData?: GameData;
+  solution: Solution;
+}
+
+interface VerifyResult {
+  valid: boolean;
+  solved: boolean;
+  invalidStep: number;
+  moves: number;
+  positions: Pos[];
+}
+
+interface GameVerifySolutionRes {
+  success: boolean;
+  errMessage: string;
+  result: VerifyResult;
+  validationErrors: ValidationError[];
+}
+
+interface GameValidateReq {
+  gameId?: number;
+  gameData?: GameData;
+}
+
+interface GameValidateRes {
+  success: boolean;
+  errMessage: string;
+  gameData: GameData;
+  gameShape: string;
+  goal: string;
+  md5: string;
+  validationErrors: ValidationError[];
+}
+
+interface SolveProgress {
+  jobId: number;
+  explored: number;
+  queueSize: number;
+  depth: number;
+  elapsed: number;
+}
+
+interface SolverStatus {
+  workers: number;
+  running: number;
+  queued: number;
+}
+
+interface SolverStatusRes {
+  success: boolean;
+  status: SolverStatus;
+}
+
+interface SettingGetRes {
+  success: boolean;
+  errMessage: string;
+  setting: Setting;
+}
+
+interface SettingSaveRes {
+  success: boolean;
+  errMessage: string;
+  setting: Setting;
 }
 
 interface TagCreateReq {
@@ -93,7 +380,23 @@ declare const window: Window & {
         GameImport: () => Promise<GameImportRes>;
         GameList: (arg1: GameListReq) => Promise<GameListRes>;
         GameSave: (arg1: Game) => Promise<GameSaveRes>;
-        GameSolve: (arg1: GameData) => Promise<GameSolveRes>;
+        GameSolve: (arg1: GameSolveReq) => Promise<GameSolveRes>;
+        GameSolveCancel: (arg1: GameSolveCancelReq) => Promise<GameSolveCancelRes>;
+        GameAnalyze: (arg1: GameAnalyzeReq) => Promise<GameAnalyzeRes>;
+        GameHardest: (arg1: GameHardestReq) => Promise<GameHardestRes>;
+        GameGenerate: (arg1: GameGenerateReq) => Promise<GameGenerateRes>;
+        GameHint: (arg1: GameHintReq) => Promise<GameHintRes>;
+        PlayStart: (arg1: PlayStartReq) => Promise<PlaySessionRes>;
+        PlayMove: (arg1: Step) => Promise<PlaySessionRes>;
+        PlayUndo: () => Promise<PlaySessionRes>;
+        PlayRedo: () => Promise<PlaySessionRes>;
+        PlayState: () => Promise<PlaySessionRes>;
+        GameVerifySolution: (arg1: GameVerifySolutionReq) => Promise<GameVerifySolutionRes>;
+        GameCountSolutions: (arg1: GameCountSolutionsReq) => Promise<GameCountSolutionsRes>;
+        SettingGet: () => Promise<SettingGetRes>;
+        SettingSave: (arg1: Setting) => Promise<SettingSaveRes>;
+        SolverStatus: () => Promise<SolverStatusRes>;
+        GameValidate: (arg1: GameValidateReq) => Promise<GameValidateRes>;
       };
     };
   };
@@ -113,9 +416,29 @@ declare global {
           GameImport: () => Promise<GameImportRes>;
           GameList: (req: GameListReq) => Promise<GameListRes>;
           GameSave: (req: Partial<Game>) => Promise<GameSaveRes>;
-          GameSolve: (req: GameData) => Promise<GameSolveRes>;
+          GameSolve: (req: GameSolveReq) => Promise<GameSolveRes>;
+          GameSolveCancel: (req: GameSolveCancelReq) => Promise<GameSolveCancelRes>;
+          GameAnalyze: (req: GameAnalyzeReq) => Promise<GameAnalyzeRes>;
+          GameHardest: (req: GameHardestReq) => Promise<GameHardestRes>;
+          GameGenerate: (req: GameGenerateReq) => Promise<GameGenerateRes>;
+          GameHint: (req: GameHintReq) => Promise<GameHintRes>;
+          PlayStart: (req: PlayStartReq) => Promise<PlaySessionRes>;
+          PlayMove: (step: Step) => Promise<PlaySessionRes>;
+          PlayUndo: () => Promise<PlaySessionRes>;
+          PlayRedo: () => Promise<PlaySessionRes>;
+          PlayState: () => Promise<PlaySessionRes>;
+          GameVerifySolution: (req: GameVerifySolutionReq) => Promise<GameVerifySolutionRes>;
+          GameCountSolutions: (req: GameCountSolutionsReq) => Promise<GameCountSolutionsRes>;
+          SettingGet: () => Promise<SettingGetRes>;
+          SettingSave: (setting: Setting) => Promise<SettingSaveRes>;
+          SolverStatus: () => Promise<SolverStatusRes>;
+          GameValidate: (req: GameValidateReq) => Promise<GameValidateRes>;
         };
       };
     };
+    runtime: {
+      EventsOn: (eventName: string, callback: (...data: any[]) => void) => void;
+      EventsOff: (eventName: string) => void;
+    };
   }
 }
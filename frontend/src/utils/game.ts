@@ -3,16 +3,21 @@ export default class GameUtils {
   /**
    * 数据库的布局数据转换成布局
    */
-  static gameShape2GameData(gDataStr: string) {
+  static gameShape2GameData(gDataStr: string, goalStr?: string) {
     try {
       const gDataObj: number[][] = JSON.parse(gDataStr);
       const boardRows = gDataObj.length - 2;
       const boardCols = gDataObj[0].length - 2;
       const kingPieceIndex = 0;
       const inBoardList: Shape[] = [];
+      const walls: Pos[] = [];
       for (let i = 1; i < gDataObj.length - 1; i++) {
         for (let j = 1; j < gDataObj[i].length - 1; j++) {
           const value = gDataObj[i][j];
+          // 边缘以内的墙
+          if (value === -2) {
+            walls.push([i - 1, j - 1]);
+          }
           if (value >= 0) {
             if (!inBoardList[value]) {
               inBoardList[value] = Array(boardRows)
@@ -47,6 +52,18 @@ export default class GameUtils {
       }
 
       const game = GameUtils.makeGameData(boardRows, boardCols, pieceList, kingPieceIndex, door);
+      if (goalStr) {
+        // 没有王棋的布局在获胜条件中记录 noKing，与后端 utils.GameShapeGoal2GameData 相同
+        const { noKing, ...goal } = JSON.parse(goalStr);
+        game.goal = goal;
+        if (noKing) {
+          game.kingPieceIndex = -1;
+          game.kingWinPos = [-1, -1];
+        }
+      }
+      if (walls.length > 0) {
+        game.walls = walls;
+      }
 
       return game;
     } catch (err) {
@@ -99,6 +116,10 @@ export default class GameUtils {
         boardWithSide[i][j] = -1;
       }
     }
+    // 棋盘内的墙
+    (gameData.walls || []).forEach(([row, col]) => {
+      boardWithSide[row + 1][col + 1] = -2;
+    });
     // 把王棋的index设为0
     const pieceList = [gameData.pieceList[gameData.kingPieceIndex]];
     for (let i = 0; i < gameData.pieceList.length; i++) {
@@ -145,6 +166,7 @@ export default class GameUtils {
     return JSON.stringify(boardWithSide);
   }
 
+  // 保存时后端会按规范摘要重新计算md5，这里的结果只作为初始值
   static gameData2Md5(gameData: GameData) {
     const data = [];
     data.push({ boardRows: gameData.boardRows });
@@ -152,6 +174,13 @@ export default class GameUtils {
     data.push({ kingWinPos: gameData.kingWinPos });
     data.push({ kingPieceShape: gameData.pieceList[gameData.kingPieceIndex].shape });
     data.push({ kingPiecePosition: gameData.pieceList[gameData.kingPieceIndex].position });
+    // 有获胜条件或墙时计入，没有的布局保持原来的md5
+    if (gameData.goal) {
+      data.push({ goal: gameData.goal });
+    }
+    if (gameData.walls && gameData.walls.length > 0) {
+      data.push({ walls: gameData.walls });
+    }
     const pieceList = [...gameData.pieceList];
     pieceList.sort((a, b) => {
       return a.position[0] - b.position[0] === 0
@@ -297,6 +326,16 @@ export default class GameUtils {
         break;
     }
     svg.appendChild(doorPath);
+    // 棋盘内的墙
+    (gameData.walls || []).forEach(([row, col]) => {
+      const wall = document.createElementNS('http://www.w3.org/2000/svg', 'rect');
+      wall.setAttribute('x', (col * gridSize + borderSize).toString());
+      wall.setAttribute('y', (row * gridSize + borderSize).toString());
+      wall.setAttribute('width', gridSize.toString());
+      wall.setAttribute('height', gridSize.toString());
+      wall.setAttribute('fill', '#555');
+      svg.appendChild(wall);
+    });
     // 棋子
     for (let i = 0; i < gameData.pieceList.length; i++) {
       const paths = GameUtils.shape2PathsWithHoles(
@@ -747,6 +786,55 @@ export default class GameUtils {
     }
     return result;
   };
+
+  /**
+   * 一步的逐格移动方向，没有 path 时整步作为一格；backward 为撤销这一步时的逐格方向
+   */
+  static stepPath(step: Step, backward = false): [number, number][] {
+    const path: [number, number][] = step.path && step.path.length > 0 ? step.path : [step.direction];
+    if (!backward) {
+      return path;
+    }
+    return path.map(([row, col]) => [-row, -col] as [number, number]).reverse();
+  }
+
+  /**
+   * 棋子从当前位置逐格移动到 target 经过的方向，与 calcPieceNextPostions 一样只经过空格，走不到时为空
+   */
+  static calcPiecePath(board: number[][], piece: Piece, pieceIndex: number, target: number[]) {
+    const key = (position: number[]) => position[0] * board[0].length + position[1];
+    const movable = (position: number[]) => piece.shape.every((row, ri) => row.every((grid, ci) => {
+      if (!grid) {
+        return true;
+      }
+      const r = position[0] + ri;
+      const c = position[1] + ci;
+      return r >= 0 && r < board.length && c >= 0 && c < board[0].length && (board[r][c] === -1 || board[r][c] === pieceIndex);
+    }));
+    const directions: [number, number][] = [[0, 1], [1, 0], [0, -1], [-1, 0]];
+    // 广度优先搜索，记录到达每个位置的前一个位置和方向
+    const from = new Map<number, { position: number[]; direction: [number, number] }>();
+    const queue = [piece.position as number[]];
+    from.set(key(piece.position), { position: [], direction: [0, 0] });
+    while (queue.length > 0) {
+      const position = queue.shift() as number[];
+      if (position[0] === target[0] && position[1] === target[1]) {
+        const path: [number, number][] = [];
+        for (let p = position; key(p) !== key(piece.position); p = (from.get(key(p)) as { position: number[] }).position) {
+          path.unshift((from.get(key(p)) as { direction: [number, number] }).direction);
+        }
+        return path;
+      }
+      for (const direction of directions) {
+        const next = [position[0] + direction[0], position[1] + direction[1]];
+        if (!from.has(key(next)) && movable(next)) {
+          from.set(key(next), { position, direction });
+          queue.push(next);
+        }
+      }
+    }
+    return [];
+  }
 }
 
 function printShapeFillNum(shapeFillNum: number[][]) {
@@ -0,0 +1,35 @@
+import { useMemoizedFn, useUnmount } from 'ahooks';
+import { useRef, useState } from 'react';
+
+// 逐格移动时每格之间的间隔，毫秒
+const CELL_MOVE_INTERVAL = 120;
+
+/**
+ * 按一步的逐格方向依次移动棋子，不会一下跳过中间的棋子
+ * moveCell 每走一格调用一次，全部走完之前 moving 为 true，此时不应开始新的移动
+ */
+export function usePieceMove() {
+  const [moving, setMoving] = useState(false);
+  const timers = useRef<number[]>([]);
+
+  useUnmount(() => {
+    timers.current.forEach((timer) => clearTimeout(timer));
+  });
+
+  const movePiece = useMemoizedFn((path: [number, number][], moveCell: (direction: [number, number]) => void) => {
+    if (path.length === 0) {
+      return;
+    }
+    setMoving(true);
+    timers.current = path.map((direction, i) =>
+      window.setTimeout(() => {
+        moveCell(direction);
+        if (i === path.length - 1) {
+          setMoving(false);
+        }
+      }, i * CELL_MOVE_INTERVAL)
+    );
+  });
+
+  return { moving, movePiece };
+}
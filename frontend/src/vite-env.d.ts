@@ -10,12 +10,23 @@ type Tag = {
   name: string;
 }
 
+type Setting = {
+  id?: number;
+  maxStates: number;
+  maxTime: number;
+  maxMemory: number;
+}
+
 type Game = {
   id?: number;
   name: string;
   gameShape: string;
+  goal?: string;
+  solveStats?: string;
   tags: Tag[];
   md5: string;
+  hashVersion?: number;
+  gameData?: GameData; // 保存时提交设计器中的布局，由后端生成 gameShape 和 goal
 };
 
 type Shape = boolean[][];
@@ -40,10 +51,27 @@ type Door = {
 type Step = {
   pieceIndex: number;
   direction: [number, number];
+  path?: [number, number][];
 };
 
 type Solution = Step[];
 
+type PieceGoal = {
+  pieceIndex: number;
+  position: Pos;
+};
+
+type RegionGoal = {
+  shape: Shape;
+  cells: Pos[];
+};
+
+type Goal = {
+  pieces?: PieceGoal[];
+  layout?: Pos[];
+  regions?: RegionGoal[];
+};
+
 type GameData = {
   pieceList: Piece[];
   kingPieceIndex: number;
@@ -51,6 +79,8 @@ type GameData = {
   boardCols: number;
   kingWinPos: Pos;
   door: Door;
+  goal?: Goal;
+  walls?: Pos[];
   solution?: Solution;
 };
 
@@ -34,6 +34,7 @@ func main() {
 		Height:     840,
 		Assets:     assets,
 		OnStartup:  application.StartUp,
+		OnShutdown: application.ShutDown,
 		Menu:       menus,
 		Fullscreen: false,
 		Bind: []interface{}{